@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	_ "time/tzdata"
 
+	"go_final_project/config"
 	"go_final_project/internal/database"
 	"go_final_project/internal/logger"
 	"go_final_project/internal/scheduler"
@@ -13,20 +15,47 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		code := runMigrate(os.Args[2:])
+		logger.CloseLogger()
+		os.Exit(code)
+	}
+
 	port := getPort()
 
 	defer logger.CloseLogger()
 
-	logger.LogMessage("[INFO] Запуск инициализации базы данных")
-	if err := database.InitDB(); err != nil {
+	store, err := openStore()
+	if err != nil {
 		logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка инициализации базы данных: %v", err))
 		return
 	}
 	defer database.CloseDB()
 
+	if err := scheduler.InitCalendar(config.GetCalendarFilePath()); err != nil {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка загрузки календаря: %v", err))
+		return
+	}
+
+	if err := scheduler.InitLocation(config.GetTimezone()); err != nil {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка установки часового пояса: %v", err))
+		return
+	}
+
+	if err := task.InitDonePolicy(config.GetDonePolicy()); err != nil {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка установки политики выполнения задач: %v", err))
+		return
+	}
+
+	if err := task.InitTrashRetention(config.GetTrashRetention()); err != nil {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка установки срока хранения корзины: %v", err))
+		return
+	}
+	go task.RunTrashPurger(store)
+
 	logger.LogMessage(fmt.Sprintf("[INFO] Сервер запущен. Порт: %s", port))
 
-	if err := runServer(port); err != nil {
+	if err := runServer(port, store); err != nil {
 		logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка запуска сервера: %v", err))
 	}
 }
@@ -39,18 +68,40 @@ func getPort() string {
 	return fmt.Sprintf("%d", tests.Port)
 }
 
-func runServer(port string) error {
-	db := database.GetDB()
+// openStore открывает хранилище задач, выбранное в TODO_DSN
+func openStore() (task.TaskStore, error) {
+	if config.GetDSN() == "memory" {
+		logger.LogMessage("[INFO] Задачи хранятся в памяти")
+		return task.NewMemoryStore(), nil
+	}
+	logger.LogMessage("[INFO] Запуск инициализации базы данных")
+	if err := database.InitDB(); err != nil {
+		return nil, err
+	}
+	return task.NewSQLStore(database.GetDB()), nil
+}
+
+func runServer(port string, store task.TaskStore) error {
 	mux := http.NewServeMux()
 
-	mux.HandleFunc("POST /api/task", task.AddTaskHandler(db))
-	mux.HandleFunc("GET /api/task", task.GetTaskHandler(db))
-	mux.HandleFunc("PUT /api/task", task.EditTaskHandler(db))
-	mux.HandleFunc("DELETE /api/task", task.DoneTaskHandler(db))
+	mux.HandleFunc("POST /api/task", task.AddTaskHandler(store))
+	mux.HandleFunc("GET /api/task", task.GetTaskHandler(store))
+	mux.HandleFunc("PUT /api/task", task.EditTaskHandler(store))
+	mux.HandleFunc("DELETE /api/task", task.DoneTaskHandler(store))
 
 	mux.HandleFunc("/api/nextdate", scheduler.NextDateHandler())
-	mux.Handle("/api/task/done", scheduler.AuthMiddleware(task.DoneTaskHandler(db)))
-	mux.Handle("/api/tasks", scheduler.AuthMiddleware(task.GetTasksHandler(db)))
+	mux.HandleFunc("GET /api/nextdates", scheduler.OccurrencesHandler())
+	mux.HandleFunc("GET /api/rrule", scheduler.RRuleHandler())
+	mux.Handle("/api/task/done", scheduler.AuthMiddleware(task.DoneTaskHandler(store)))
+	mux.Handle("/api/task/exdate", scheduler.AuthMiddleware(task.ExDateHandler(store)))
+	mux.Handle("/api/task/checklist", scheduler.AuthMiddleware(task.ChecklistHandler(store)))
+	mux.Handle("/api/task/deps", scheduler.AuthMiddleware(task.DepsHandler(store)))
+	mux.Handle("/api/task/history", scheduler.AuthMiddleware(task.HistoryHandler(store)))
+	mux.Handle("/api/trash", scheduler.AuthMiddleware(task.TrashHandler(store)))
+	mux.Handle("/api/trash/restore", scheduler.AuthMiddleware(task.RestoreHandler(store)))
+	mux.Handle("/api/tasks", scheduler.AuthMiddleware(task.GetTasksHandler(store)))
+	mux.Handle("/api/tags", scheduler.AuthMiddleware(task.TagsHandler(store)))
+	mux.Handle("/api/projects", scheduler.AuthMiddleware(task.ProjectsHandler(store)))
 
 	mux.Handle("/", http.FileServer(http.Dir("web")))
 	mux.HandleFunc("/api/signin", scheduler.SignInHandler)
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"go_final_project/config"
+	"go_final_project/internal/database"
+)
+
+const migrateUsage = `Использование: go_final_project migrate [-dry-run] [-to N] up|down|status
+  up      применить миграции до версии N (по умолчанию — до последней)
+  down    откатить миграции до версии N (по умолчанию — на одну версию)
+  status  показать текущую и последнюю версии схемы
+`
+
+// runMigrate выполняет команду migrate и возвращает код завершения
+func runMigrate(args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, migrateUsage) }
+	dryRun := flags.Bool("dry-run", false, "напечатать SQL ожидающих миграций, не выполняя его")
+	target := flags.Int("to", -1, "целевая версия схемы")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	if config.GetDSN() == "memory" {
+		fmt.Fprintln(os.Stderr, "хранилище в памяти не использует миграции")
+		return 1
+	}
+	if err := database.OpenDB(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer database.CloseDB()
+
+	current, err := database.SchemaVersion()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	switch flags.Arg(0) {
+	case "up":
+		if *target < 0 {
+			*target = database.LatestVersion()
+		}
+		err = database.MigrateUp(*target, *dryRun)
+	case "down":
+		if *target < 0 {
+			*target = max(current-1, 0)
+		}
+		err = database.MigrateDown(*target, *dryRun)
+	case "status":
+		fmt.Printf("Версия схемы: %d, последняя версия: %d\n", current, database.LatestVersion())
+		return 0
+	default:
+		flags.Usage()
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if !*dryRun {
+		if current, err = database.SchemaVersion(); err == nil {
+			fmt.Printf("Версия схемы: %d\n", current)
+		}
+	}
+	return 0
+}
@@ -19,3 +19,30 @@ func GetDBFilePath() string {
 
 	return filepath.Join(dataDir, "scheduler.db")
 }
+
+// GetDSN возвращает строку подключения к хранилищу задач из TODO_DSN:
+// postgres://... — PostgreSQL, memory — хранение в памяти,
+// пустая строка — SQLite в файле GetDBFilePath
+func GetDSN() string {
+	return os.Getenv("TODO_DSN")
+}
+
+// GetCalendarFilePath возвращает путь к файлу календаря праздников из TODO_CALENDAR
+func GetCalendarFilePath() string {
+	return os.Getenv("TODO_CALENDAR")
+}
+
+// GetTimezone возвращает часовой пояс по умолчанию из TODO_TZ
+func GetTimezone() string {
+	return os.Getenv("TODO_TZ")
+}
+
+// GetDonePolicy возвращает политику выполнения задач с чек-листом из TODO_DONE_POLICY
+func GetDonePolicy() string {
+	return os.Getenv("TODO_DONE_POLICY")
+}
+
+// GetTrashRetention возвращает срок хранения задач в корзине в днях из TODO_TRASH_RETENTION
+func GetTrashRetention() string {
+	return os.Getenv("TODO_TRASH_RETENTION")
+}
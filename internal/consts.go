@@ -3,3 +3,10 @@ package internal
 const DateLayout = "20060102"
 const DateFormatDDMMYYYY = "02.01.2006"
 const TaskLimit = 50
+const MaxTaskLimit = 500
+const OccurrenceLimit = 100
+const TimeLayout = "15:04"
+const ISODateLayout = "2006-01-02"
+const HighestPriority = 1
+const LowestPriority = 4
+const MaxRepeatLength = 1024
@@ -2,62 +2,63 @@ package database
 
 import (
 	"fmt"
-	"os"
+	"strings"
 
 	"go_final_project/config"
 	"go_final_project/internal/logger"
 
 	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
 	_ "modernc.org/sqlite"
 )
 
 var DB *sqlx.DB
 
+// InitDB открывает базу данных и обновляет её схему до последней версии
 func InitDB() error {
-	dbFile := config.GetDBFilePath()
-	logger.LogMessage(fmt.Sprintf("[INFO] Расположение базы данных: %s", dbFile))
+	if err := OpenDB(); err != nil {
+		return err
+	}
+	if err := MigrateUp(0, false); err != nil {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка при обновлении схемы базы данных: %v", err))
+		DB.Close()
+		return err
+	}
+	logger.LogMessage("[INFO] Инициализация базы данных завершена успешно")
+	return nil
+}
 
-	_, err := os.Stat(dbFile)
-	install := os.IsNotExist(err)
+// OpenDB открывает базу данных без изменения её схемы: PostgreSQL, если
+// TODO_DSN содержит адрес postgres://, иначе SQLite в файле TODO_DBFILE
+func OpenDB() error {
+	driver, source := "sqlite", config.GetDBFilePath()
+	if dsn := config.GetDSN(); IsPostgres(dsn) {
+		driver, source = "postgres", dsn
+		logger.LogMessage("[INFO] База данных: PostgreSQL")
+	} else {
+		logger.LogMessage(fmt.Sprintf("[INFO] Расположение базы данных: %s", source))
+	}
 
-	db, err := sqlx.Open("sqlite", dbFile)
+	if driver == "sqlite" {
+		// Запись в транзакции ждёт освобождения базы, а не завершается ошибкой SQLITE_BUSY
+		source += "?_pragma=busy_timeout(5000)"
+	}
+	db, err := sqlx.Open(driver, source)
+	if err == nil {
+		err = db.Ping()
+	}
 	if err != nil {
 		logger.LogMessage(fmt.Sprintf("[ERROR] Не удалось открыть базу данных: %v", err))
 		return fmt.Errorf("не удалось открыть базу данных: %v", err)
 	}
 
 	DB = db
-	if install {
-		logger.LogMessage("[INFO] База данных не найдена, начинаем создание таблиц")
-		if err := createTables(); err != nil {
-			logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка при создании таблиц: %v", err))
-			db.Close()
-			return err
-		}
-		logger.LogMessage("[INFO] База данных создана.")
-	}
-	logger.LogMessage("[INFO] Инициализация базы данных завершена успешно")
 	return nil
 }
 
-func createTables() error {
-	const query = `
-	CREATE TABLE scheduler (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		date TEXT NOT NULL,
-		title TEXT NOT NULL,
-		comment TEXT,
-		repeat TEXT CHECK(length(repeat) <= 128)
-	);
-	CREATE INDEX idx_date ON scheduler (date);
-	`
-	_, err := DB.Exec(query)
-	if err != nil {
-		logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка создания таблицы: %v", err))
-		return fmt.Errorf("ошибка создания таблицы: %v", err)
-	}
-
-	return nil
+// IsPostgres проверяет, указывает ли строка подключения на PostgreSQL
+func IsPostgres(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")
 }
 
 func CloseDB() {
@@ -0,0 +1,493 @@
+package database
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"go_final_project/internal/logger"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Migration — версия схемы базы данных: Up переводит схему на эту версию,
+// Down возвращает её на предыдущую. Каждая миграция выполняется в отдельной транзакции.
+// PostgresUp и PostgresDown, если заданы, заменяют Up и Down для PostgreSQL.
+type Migration struct {
+	Version      int
+	Name         string
+	Up           []string
+	Down         []string
+	PostgresUp   []string
+	PostgresDown []string
+}
+
+// migrations — все версии схемы по порядку. Уже выпущенные миграции не меняются,
+// изменения схемы добавляются новой миграцией в конец списка.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_scheduler",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS scheduler (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				date TEXT NOT NULL,
+				title TEXT NOT NULL,
+				comment TEXT,
+				repeat TEXT CHECK(length(repeat) <= 128)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_date ON scheduler (date)`,
+		},
+		Down: []string{
+			`DROP TABLE scheduler`,
+		},
+	},
+	{
+		Version: 2,
+		Name:    "create_scheduler_exdates",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS scheduler_exdates (
+				task_id INTEGER NOT NULL,
+				date TEXT NOT NULL,
+				PRIMARY KEY (task_id, date)
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE scheduler_exdates`,
+		},
+	},
+	{
+		Version: 3,
+		Name:    "add_task_time",
+		Up: []string{
+			addColumn("scheduler", "time", "TEXT NOT NULL DEFAULT ''"),
+			addColumn("scheduler", "timezone", "TEXT NOT NULL DEFAULT ''"),
+		},
+		Down: []string{
+			`ALTER TABLE scheduler DROP COLUMN timezone`,
+			`ALTER TABLE scheduler DROP COLUMN time`,
+		},
+	},
+	{
+		Version: 4,
+		Name:    "add_task_priority",
+		Up: []string{
+			addColumn("scheduler", "priority", "INTEGER NOT NULL DEFAULT 4"),
+		},
+		Down: []string{
+			`ALTER TABLE scheduler DROP COLUMN priority`,
+		},
+	},
+	{
+		Version: 5,
+		Name:    "create_tags",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS tags (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL UNIQUE
+			)`,
+			`CREATE TABLE IF NOT EXISTS scheduler_tags (
+				task_id INTEGER NOT NULL,
+				tag_id INTEGER NOT NULL,
+				PRIMARY KEY (task_id, tag_id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_scheduler_tags_tag ON scheduler_tags (tag_id)`,
+		},
+		Down: []string{
+			`DROP TABLE scheduler_tags`,
+			`DROP TABLE tags`,
+		},
+	},
+	{
+		Version: 6,
+		Name:    "create_projects",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS projects (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL,
+				color TEXT NOT NULL DEFAULT '',
+				archived INTEGER NOT NULL DEFAULT 0
+			)`,
+			addColumn("scheduler", "project_id", "INTEGER"),
+		},
+		Down: []string{
+			`ALTER TABLE scheduler DROP COLUMN project_id`,
+			`DROP TABLE projects`,
+		},
+	},
+	{
+		Version: 7,
+		Name:    "create_scheduler_checklist",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS scheduler_checklist (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				task_id INTEGER NOT NULL,
+				title TEXT NOT NULL,
+				done INTEGER NOT NULL DEFAULT 0,
+				position INTEGER NOT NULL DEFAULT 0
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_scheduler_checklist_task ON scheduler_checklist (task_id)`,
+		},
+		Down: []string{
+			`DROP TABLE scheduler_checklist`,
+		},
+	},
+	{
+		Version: 8,
+		Name:    "create_scheduler_deps",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS scheduler_deps (
+				task_id INTEGER NOT NULL,
+				blocked_by INTEGER NOT NULL,
+				PRIMARY KEY (task_id, blocked_by)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_scheduler_deps_blocked_by ON scheduler_deps (blocked_by)`,
+		},
+		Down: []string{
+			`DROP TABLE scheduler_deps`,
+		},
+	},
+	{
+		Version: 9,
+		Name:    "create_scheduler_completions",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS scheduler_completions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				task_id INTEGER NOT NULL,
+				scheduled_date TEXT NOT NULL,
+				scheduled_time TEXT NOT NULL DEFAULT '',
+				completed_at TEXT NOT NULL,
+				note TEXT NOT NULL DEFAULT ''
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_scheduler_completions_task ON scheduler_completions (task_id)`,
+		},
+		Down: []string{
+			`DROP TABLE scheduler_completions`,
+		},
+	},
+	{
+		Version: 10,
+		Name:    "add_task_trash",
+		Up: []string{
+			addColumn("scheduler", "deleted_at", "TEXT NOT NULL DEFAULT ''"),
+			addColumn("scheduler", "completed_at", "TEXT NOT NULL DEFAULT ''"),
+		},
+		Down: []string{
+			`ALTER TABLE scheduler DROP COLUMN completed_at`,
+			`ALTER TABLE scheduler DROP COLUMN deleted_at`,
+		},
+	},
+	{
+		Version: 11,
+		Name:    "create_scheduler_search",
+		// Полнотекстовый индекс по заголовку и комментарию; unicode61 приводит
+		// к нижнему регистру любые буквы, в том числе кириллицу
+		Up: []string{
+			`CREATE VIRTUAL TABLE IF NOT EXISTS scheduler_fts USING fts5(
+				title, comment,
+				content = 'scheduler', content_rowid = 'id',
+				tokenize = 'unicode61 remove_diacritics 2'
+			)`,
+			schedulerFTSTriggers[0],
+			schedulerFTSTriggers[1],
+			schedulerFTSTriggers[2],
+			`INSERT INTO scheduler_fts (scheduler_fts) VALUES ('rebuild')`,
+		},
+		Down: []string{
+			`DROP TRIGGER scheduler_fts_update`,
+			`DROP TRIGGER scheduler_fts_delete`,
+			`DROP TRIGGER scheduler_fts_insert`,
+			`DROP TABLE scheduler_fts`,
+		},
+		PostgresUp: []string{
+			`CREATE INDEX IF NOT EXISTS idx_scheduler_search ON scheduler
+				USING GIN (to_tsvector('simple', title || ' ' || coalesce(comment, '')))`,
+		},
+		PostgresDown: []string{
+			`DROP INDEX idx_scheduler_search`,
+		},
+	},
+	{
+		Version: 12,
+		Name:    "add_deps_resolved",
+		// Снятая выполнением зависимость не удаляется: повторяющаяся задача
+		// при переходе к следующей дате снова ждёт свои блокирующие задачи
+		Up: []string{
+			addColumn("scheduler_deps", "resolved", "INTEGER NOT NULL DEFAULT 0"),
+		},
+		Down: []string{
+			`ALTER TABLE scheduler_deps DROP COLUMN resolved`,
+		},
+	},
+	{
+		Version: 13,
+		Name:    "drop_repeat_length_check",
+		// Правила с until, count, every и переведённые из RRULE бывают длиннее 128 символов;
+		// длина правила проверяется при сохранении задачи. SQLite не удаляет ограничение
+		// CHECK из таблицы, поэтому таблица scheduler пересоздаётся.
+		Up:   rebuildScheduler("repeat TEXT"),
+		Down: rebuildScheduler("repeat TEXT CHECK(length(repeat) <= 128)"),
+		PostgresUp: []string{
+			`ALTER TABLE scheduler DROP CONSTRAINT IF EXISTS scheduler_repeat_check`,
+		},
+		PostgresDown: []string{
+			`ALTER TABLE scheduler ADD CONSTRAINT scheduler_repeat_check CHECK (length(repeat) <= 128)`,
+		},
+	},
+	{
+		Version: 14,
+		Name:    "add_task_anchor_date",
+		// Дата повторения по графику до переноса roll: от неё, а не от перенесённой
+		// даты задачи, отсчитывается интервал every следующего повторения
+		Up: []string{
+			addColumn("scheduler", "anchor_date", "TEXT NOT NULL DEFAULT ''"),
+		},
+		Down: []string{
+			`ALTER TABLE scheduler DROP COLUMN anchor_date`,
+		},
+	},
+}
+
+// schedulerFTSTriggers поддерживают полнотекстовый индекс scheduler_fts
+// в соответствии с таблицей scheduler
+var schedulerFTSTriggers = []string{
+	`CREATE TRIGGER IF NOT EXISTS scheduler_fts_insert AFTER INSERT ON scheduler BEGIN
+				INSERT INTO scheduler_fts (rowid, title, comment) VALUES (new.id, new.title, new.comment);
+			END`,
+	`CREATE TRIGGER IF NOT EXISTS scheduler_fts_delete AFTER DELETE ON scheduler BEGIN
+				INSERT INTO scheduler_fts (scheduler_fts, rowid, title, comment) VALUES ('delete', old.id, old.title, old.comment);
+			END`,
+	`CREATE TRIGGER IF NOT EXISTS scheduler_fts_update AFTER UPDATE OF title, comment ON scheduler BEGIN
+				INSERT INTO scheduler_fts (scheduler_fts, rowid, title, comment) VALUES ('delete', old.id, old.title, old.comment);
+				INSERT INTO scheduler_fts (rowid, title, comment) VALUES (new.id, new.title, new.comment);
+			END`,
+}
+
+// rebuildScheduler возвращает запросы SQLite, которые пересоздают таблицу scheduler
+// с определением столбца repeat: данные, счётчик идентификаторов, индекс и триггеры
+// полнотекстового поиска сохраняются
+func rebuildScheduler(repeatColumn string) []string {
+	const columns = "id, date, title, comment, repeat, time, timezone, priority, project_id, deleted_at, completed_at"
+	return append([]string{
+		`CREATE TABLE scheduler_new (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				date TEXT NOT NULL,
+				title TEXT NOT NULL,
+				comment TEXT,
+				` + repeatColumn + `,
+				time TEXT NOT NULL DEFAULT '',
+				timezone TEXT NOT NULL DEFAULT '',
+				priority INTEGER NOT NULL DEFAULT 4,
+				project_id INTEGER,
+				deleted_at TEXT NOT NULL DEFAULT '',
+				completed_at TEXT NOT NULL DEFAULT ''
+			)`,
+		`INSERT INTO scheduler_new (` + columns + `) SELECT ` + columns + ` FROM scheduler`,
+		// Идентификаторы удалённых задач не выдаются повторно: на них ссылается история выполнений
+		`DELETE FROM sqlite_sequence WHERE name = 'scheduler_new'`,
+		`INSERT INTO sqlite_sequence (name, seq) SELECT 'scheduler_new', seq FROM sqlite_sequence WHERE name = 'scheduler'`,
+		`DROP TABLE scheduler`,
+		`ALTER TABLE scheduler_new RENAME TO scheduler`,
+		`CREATE INDEX IF NOT EXISTS idx_date ON scheduler (date)`,
+	}, schedulerFTSTriggers...)
+}
+
+// migrationOutput — куда печатается SQL в режиме dry-run
+var migrationOutput io.Writer = os.Stdout
+
+// addColumnRe разбирает запрос добавления столбца, построенный addColumn
+var addColumnRe = regexp.MustCompile(`^ALTER TABLE (\w+) ADD COLUMN (\w+) `)
+
+// postgresReplacer переводит запросы миграций, написанные для SQLite, на диалект PostgreSQL
+var postgresReplacer = strings.NewReplacer(
+	"INTEGER PRIMARY KEY AUTOINCREMENT", "BIGSERIAL PRIMARY KEY",
+	" ADD COLUMN ", " ADD COLUMN IF NOT EXISTS ",
+)
+
+func isPostgres() bool {
+	return DB.DriverName() == "postgres"
+}
+
+// dialectQuery приводит запрос миграции к диалекту открытой базы данных
+func dialectQuery(query string) string {
+	if isPostgres() {
+		return postgresReplacer.Replace(query)
+	}
+	return query
+}
+
+func addColumn(table, column, definition string) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)
+}
+
+// LatestVersion возвращает номер последней известной версии схемы
+func LatestVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaVersion возвращает текущую версию схемы базы данных, 0 — для пустой базы
+func SchemaVersion() (int, error) {
+	var version int
+	query := "SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version'"
+	if isPostgres() {
+		query = "SELECT count(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = 'schema_version'"
+	}
+	err := DB.Get(&version, query)
+	if err == nil && version > 0 {
+		err = DB.Get(&version, "SELECT coalesce(max(version), 0) FROM schema_version")
+	}
+	if err != nil {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка чтения версии схемы: %v", err))
+		return 0, fmt.Errorf("ошибка чтения версии схемы: %v", err)
+	}
+	return version, nil
+}
+
+// MigrateUp применяет миграции до версии target включительно (0 — до последней).
+// При dryRun запросы не выполняются, а печатаются в migrationOutput.
+func MigrateUp(target int, dryRun bool) error {
+	if target == 0 {
+		target = LatestVersion()
+	}
+	current, err := SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if target < current || target > LatestVersion() {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Некорректная версия схемы для обновления: %d", target))
+		return fmt.Errorf("некорректная версия схемы для обновления: %d", target)
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current || m.Version > target {
+			continue
+		}
+		if err := applyMigration(m, true, dryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateDown откатывает миграции, пока версия схемы не станет равна target
+func MigrateDown(target int, dryRun bool) error {
+	current, err := SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if target < 0 || target > current {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Некорректная версия схемы для отката: %d", target))
+		return fmt.Errorf("некорректная версия схемы для отката: %d", target)
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version > current || m.Version <= target {
+			continue
+		}
+		if err := applyMigration(m, false, dryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ensureSchemaVersion() error {
+	_, err := DB.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка создания таблицы schema_version: %v", err))
+		return fmt.Errorf("ошибка создания таблицы schema_version: %v", err)
+	}
+	return nil
+}
+
+// queries возвращает запросы миграции для открытой базы данных: Up (up) или Down
+func (m Migration) queries(up bool) []string {
+	switch {
+	case up && isPostgres() && m.PostgresUp != nil:
+		return m.PostgresUp
+	case !up && isPostgres() && m.PostgresDown != nil:
+		return m.PostgresDown
+	case up:
+		return m.Up
+	}
+	return m.Down
+}
+
+// applyMigration выполняет в одной транзакции запросы Up (up) или Down миграции m
+// и добавляет её версию в schema_version или удаляет оттуда
+func applyMigration(m Migration, up, dryRun bool) error {
+	queries, direction := m.queries(up), "откат"
+	if up {
+		direction = "применение"
+	}
+
+	if dryRun {
+		// В режиме dry-run база данных не меняется
+		fmt.Fprintf(migrationOutput, "-- %s миграции %d %s\n", direction, m.Version, m.Name)
+		for _, query := range queries {
+			fmt.Fprintf(migrationOutput, "%s;\n", strings.TrimSpace(dialectQuery(query)))
+		}
+		return nil
+	}
+
+	if err := ensureSchemaVersion(); err != nil {
+		return err
+	}
+	tx, err := DB.Beginx()
+	if err == nil {
+		for _, query := range queries {
+			if err = execMigrationQuery(tx, query); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		if up {
+			_, err = tx.Exec(tx.Rebind("INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)"),
+				m.Version, m.Name, time.Now().UTC().Format(time.RFC3339))
+		} else {
+			_, err = tx.Exec(tx.Rebind("DELETE FROM schema_version WHERE version = ?"), m.Version)
+		}
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		if tx != nil {
+			tx.Rollback()
+		}
+		logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка: %s миграции %d %s: %v", direction, m.Version, m.Name, err))
+		return fmt.Errorf("ошибка миграции %d %s: %v", m.Version, m.Name, err)
+	}
+	logger.LogMessage(fmt.Sprintf("[INFO] Выполнено %s миграции %d %s", direction, m.Version, m.Name))
+	return nil
+}
+
+// execMigrationQuery выполняет запрос миграции. Столбцы, которые уже есть
+// в базах, созданных до появления миграций, повторно не добавляются.
+func execMigrationQuery(tx *sqlx.Tx, query string) error {
+	if isPostgres() {
+		_, err := tx.Exec(dialectQuery(query))
+		return err
+	}
+	if match := addColumnRe.FindStringSubmatch(query); match != nil {
+		var count int
+		err := tx.Get(&count, "SELECT count(*) FROM pragma_table_info(?) WHERE name = ?", match[1], match[2])
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+	}
+	_, err := tx.Exec(query)
+	return err
+}
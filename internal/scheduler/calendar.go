@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go_final_project/internal"
+	"go_final_project/internal/logger"
+)
+
+// Calendar — рабочая неделя и праздничные дни для правил с рабочими днями
+type Calendar struct {
+	WorkDays map[time.Weekday]bool
+	Holidays map[string]bool
+}
+
+// calendar используется правилами bd и модификатором roll
+var calendar = DefaultCalendar()
+
+// DefaultCalendar возвращает календарь с рабочими днями с понедельника по пятницу без праздников
+func DefaultCalendar() *Calendar {
+	return &Calendar{
+		WorkDays: map[time.Weekday]bool{
+			time.Monday: true, time.Tuesday: true, time.Wednesday: true, time.Thursday: true, time.Friday: true,
+		},
+		Holidays: map[string]bool{},
+	}
+}
+
+// IsWorkday проверяет, является ли дата рабочим днём
+func (c *Calendar) IsWorkday(date time.Time) bool {
+	return c.WorkDays[date.Weekday()] && !c.Holidays[date.Format(internal.DateLayout)]
+}
+
+// InitCalendar загружает календарь из файла path. Пустой путь оставляет календарь по умолчанию.
+func InitCalendar(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	c, err := LoadCalendar(path)
+	if err != nil {
+		return err
+	}
+	calendar = c
+	logger.LogMessage(fmt.Sprintf("[INFO] Календарь загружен: %s, праздничных дней: %d", path, len(c.Holidays)))
+	return nil
+}
+
+// LoadCalendar читает календарь из JSON-файла или файла iCalendar (.ics).
+// JSON-файл содержит список праздников ["20250101", ...] либо объект
+// {"workdays": [1, 2, 3, 4, 5], "holidays": ["20250101", ...]}, где дни недели
+// нумеруются от 1 (понедельник) до 7 (воскресенье). Из .ics берутся даты DTSTART событий.
+func LoadCalendar(path string) (*Calendar, error) {
+	if strings.EqualFold(filepath.Ext(path), ".ics") {
+		return loadICSCalendar(path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Не удалось прочитать календарь: %v", err))
+		return nil, fmt.Errorf("не удалось прочитать календарь: %v", err)
+	}
+
+	var file struct {
+		WorkDays []int    `json:"workdays"`
+		Holidays []string `json:"holidays"`
+	}
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		err = json.Unmarshal(data, &file.Holidays)
+	} else {
+		err = json.Unmarshal(data, &file)
+	}
+	if err != nil {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка разбора календаря: %v", err))
+		return nil, fmt.Errorf("ошибка разбора календаря: %v", err)
+	}
+
+	c := DefaultCalendar()
+	if len(file.WorkDays) > 0 {
+		c.WorkDays = make(map[time.Weekday]bool)
+		for _, d := range file.WorkDays {
+			if d < 1 || d > 7 {
+				logger.LogMessage(fmt.Sprintf("[ERROR] Неверный день недели в календаре: %d", d))
+				return nil, fmt.Errorf("неверный день недели в календаре: %d", d)
+			}
+			c.WorkDays[time.Weekday(d%7)] = true
+		}
+	}
+	for _, h := range file.Holidays {
+		if err := c.addHoliday(h); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func loadICSCalendar(path string) (*Calendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Не удалось прочитать календарь: %v", err))
+		return nil, fmt.Errorf("не удалось прочитать календарь: %v", err)
+	}
+	defer f.Close()
+
+	c := DefaultCalendar()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// DTSTART;VALUE=DATE:20250101 или DTSTART:20250101T000000Z
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || !strings.HasPrefix(strings.ToUpper(name), "DTSTART") || len(value) < 8 {
+			continue
+		}
+		if err := c.addHoliday(value[:8]); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка чтения календаря: %v", err))
+		return nil, fmt.Errorf("ошибка чтения календаря: %v", err)
+	}
+	return c, nil
+}
+
+func (c *Calendar) addHoliday(date string) error {
+	if _, err := time.Parse(internal.DateLayout, date); err != nil {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Неверная дата в календаре: %s", date))
+		return fmt.Errorf("неверная дата в календаре: %s", date)
+	}
+	c.Holidays[date] = true
+	return nil
+}
+
+// rollDate переносит нерабочую дату на ближайший рабочий день вперёд (step = 1) или назад (step = -1)
+func (c *Calendar) rollDate(date time.Time, step int) time.Time {
+	for i := 0; i < 366 && !c.IsWorkday(date); i++ {
+		date = date.AddDate(0, 0, step)
+	}
+	return date
+}
@@ -0,0 +1,320 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go_final_project/internal"
+	"go_final_project/internal/logger"
+)
+
+const (
+	LangRU = "ru"
+	LangEN = "en"
+
+	// untilLayoutEN — формат даты окончания серии в английском описании
+	untilLayoutEN = "January 2, 2006"
+)
+
+var (
+	weekDaysRU      = []string{"понедельникам", "вторникам", "средам", "четвергам", "пятницам", "субботам", "воскресеньям"}
+	weekDaysAccRU   = []string{"понедельник", "вторник", "среду", "четверг", "пятницу", "субботу", "воскресенье"}
+	weekDayGenderRU = []int{0, 0, 1, 0, 1, 1, 2}
+	monthsRU        = []string{"январе", "феврале", "марте", "апреле", "мае", "июне", "июле", "августе", "сентябре", "октябре", "ноябре", "декабре"}
+	weekDaysEN      = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+	monthsEN        = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	ordinalsEN      = map[int]string{1: "first", 2: "second", 3: "third", 4: "fourth", 5: "fifth", -1: "last", -2: "second-to-last"}
+	ordinalsRU      = map[int][3]string{
+		1:  {"первый", "первую", "первое"},
+		2:  {"второй", "вторую", "второе"},
+		3:  {"третий", "третью", "третье"},
+		4:  {"четвёртый", "четвёртую", "четвёртое"},
+		5:  {"пятый", "пятую", "пятое"},
+		-1: {"последний", "последнюю", "последнее"},
+		-2: {"предпоследний", "предпоследнюю", "предпоследнее"},
+	}
+)
+
+// DescribeRule возвращает описание правила повторения на естественном языке.
+// Поддерживаются языки LangRU и LangEN. Правило разбирается тем же ParseRule,
+// что и в NextDate, поэтому описание есть только у корректных правил.
+func DescribeRule(repeat, lang string) (string, error) {
+	rule, err := ParseRule(repeat)
+	if err != nil {
+		return "", err
+	}
+	return rule.Describe(lang)
+}
+
+// Describe возвращает описание правила на языке lang
+func (r *Rule) Describe(lang string) (string, error) {
+	if lang != LangRU && lang != LangEN {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Неподдерживаемый язык описания: %s", lang))
+		return "", fmt.Errorf("неподдерживаемый язык описания: %s", lang)
+	}
+	ru := lang == LangRU
+
+	var text string
+	switch r.Kind {
+	case RuleDay:
+		text = describeDays(r.Interval, ru)
+	case RuleWeek:
+		text = describeWeek(r.WeekDays, r.Interval, ru)
+	case RuleWeekdayMonth:
+		text = describeWeekdayOfMonth(r.Ordinals, r.Interval, ru)
+	case RuleMonth:
+		text = describeMonth(r.MonthDays, r.Months, r.Interval, ru)
+	case RuleYear:
+		text = describeYear(r.Interval, ru)
+	case RuleBusinessDay:
+		text = describeBusinessDays(r.Interval, ru)
+	case RuleHour:
+		text = describeHours(r.Interval, ru)
+	case RuleMinute:
+		text = describeMinutes(r.Interval, ru)
+	}
+
+	if r.ActiveTo != 0 && ru {
+		text += fmt.Sprintf(" с %s до %s", formatClock(r.ActiveFrom), formatClock(r.ActiveTo))
+	} else if r.ActiveTo != 0 {
+		text += fmt.Sprintf(" between %s and %s", formatClock(r.ActiveFrom), formatClock(r.ActiveTo))
+	}
+
+	switch {
+	case r.Roll == RollNext && ru:
+		text += " с переносом на следующий рабочий день"
+	case r.Roll == RollPrev && ru:
+		text += " с переносом на предыдущий рабочий день"
+	case r.Roll == RollNext:
+		text += ", moved to the next business day"
+	case r.Roll == RollPrev:
+		text += ", moved to the previous business day"
+	}
+
+	if r.FromDone && ru {
+		text += ", считая от даты выполнения"
+	} else if r.FromDone {
+		text += ", counted from completion"
+	}
+
+	if !r.Until.IsZero() {
+		if ru {
+			text += ", до " + r.Until.Format(internal.DateFormatDDMMYYYY)
+		} else {
+			text += ", until " + r.Until.Format(untilLayoutEN)
+		}
+	}
+	if r.Count != 0 {
+		if ru {
+			text += fmt.Sprintf(", всего %d %s", r.Count, pluralRU(r.Count, "раз", "раза", "раз"))
+		} else {
+			text += fmt.Sprintf(", %d %s in total", r.Count, pluralEN(r.Count, "time", "times"))
+		}
+	}
+
+	first, size := utf8.DecodeRuneInString(text)
+	return string(unicode.ToUpper(first)) + text[size:], nil
+}
+
+func describeDays(n int, ru bool) string {
+	switch {
+	case n == 1 && ru:
+		return "каждый день"
+	case n == 1:
+		return "every day"
+	case ru:
+		return fmt.Sprintf("раз в %d %s", n, pluralRU(n, "день", "дня", "дней"))
+	default:
+		return fmt.Sprintf("every %d days", n)
+	}
+}
+
+func describeBusinessDays(n int, ru bool) string {
+	switch {
+	case n == 1 && ru:
+		return "каждый рабочий день"
+	case n == 1:
+		return "every business day"
+	case ru:
+		return fmt.Sprintf("раз в %d %s", n, pluralRU(n, "рабочий день", "рабочих дня", "рабочих дней"))
+	default:
+		return fmt.Sprintf("every %d business days", n)
+	}
+}
+
+func describeHours(n int, ru bool) string {
+	switch {
+	case n == 1 && ru:
+		return "каждый час"
+	case n == 1:
+		return "every hour"
+	case ru:
+		return fmt.Sprintf("раз в %d %s", n, pluralRU(n, "час", "часа", "часов"))
+	default:
+		return fmt.Sprintf("every %d hours", n)
+	}
+}
+
+func describeMinutes(n int, ru bool) string {
+	switch {
+	case n == 1 && ru:
+		return "каждую минуту"
+	case n == 1:
+		return "every minute"
+	case ru:
+		return fmt.Sprintf("раз в %d %s", n, pluralRU(n, "минуту", "минуты", "минут"))
+	default:
+		return fmt.Sprintf("every %d minutes", n)
+	}
+}
+
+func describeWeek(weekDays []int, interval int, ru bool) string {
+	var days []string
+	for _, d := range weekDays {
+		if ru {
+			days = append(days, weekDaysRU[d-1])
+		} else {
+			days = append(days, weekDaysEN[d-1])
+		}
+	}
+
+	switch {
+	case ru && interval == 1:
+		return "каждую неделю по " + joinWords(days, ru)
+	case ru:
+		return fmt.Sprintf("по %s раз в %d %s", joinWords(days, ru), interval, pluralRU(interval, "неделю", "недели", "недель"))
+	case interval == 1:
+		return "every week on " + joinWords(days, ru)
+	default:
+		return fmt.Sprintf("every %d weeks on %s", interval, joinWords(days, ru))
+	}
+}
+
+func describeWeekdayOfMonth(ordinals []OrdinalDay, interval int, ru bool) string {
+	var days []string
+	for _, d := range ordinals {
+		if ru {
+			days = append(days, "в "+ordinalsRU[d.Ordinal][weekDayGenderRU[d.WeekDay-1]]+" "+weekDaysAccRU[d.WeekDay-1])
+		} else {
+			days = append(days, "the "+ordinalsEN[d.Ordinal]+" "+weekDaysEN[d.WeekDay-1])
+		}
+	}
+	if ru {
+		return monthPeriod(interval, ru) + " " + joinWords(days, ru)
+	}
+	return monthPeriod(interval, ru) + " on " + joinWords(days, ru)
+}
+
+func describeMonth(monthDays, months []int, interval int, ru bool) string {
+	var numbers, special []string
+	for _, d := range monthDays {
+		switch {
+		case d == -1 && ru:
+			special = append(special, "в последний день")
+		case d == -2 && ru:
+			special = append(special, "в предпоследний день")
+		case d == -1:
+			special = append(special, "the last day")
+		case d == -2:
+			special = append(special, "the second-to-last day")
+		case ru:
+			numbers = append(numbers, fmt.Sprintf("%d-го", d))
+		default:
+			numbers = append(numbers, strconv.Itoa(d))
+		}
+	}
+
+	var days []string
+	if len(numbers) > 0 {
+		if ru {
+			days = append(days, joinWords(numbers, ru)+" числа")
+		} else {
+			days = append(days, "day "+joinWords(numbers, ru))
+		}
+	}
+	days = append(days, special...)
+
+	var text string
+	if ru {
+		text = monthPeriod(interval, ru) + " " + joinWords(days, ru)
+	} else {
+		text = monthPeriod(interval, ru) + " on " + joinWords(days, ru)
+	}
+
+	if len(months) > 0 {
+		var names []string
+		for _, m := range months {
+			if ru {
+				names = append(names, monthsRU[m-1])
+			} else {
+				names = append(names, monthsEN[m-1])
+			}
+		}
+		if ru {
+			text += " в " + joinWords(names, ru)
+		} else {
+			text += " in " + joinWords(names, ru)
+		}
+	}
+	return text
+}
+
+func describeYear(interval int, ru bool) string {
+	switch {
+	case interval == 1 && ru:
+		return "каждый год"
+	case interval == 1:
+		return "every year"
+	case ru:
+		return fmt.Sprintf("раз в %d %s", interval, pluralRU(interval, "год", "года", "лет"))
+	default:
+		return fmt.Sprintf("every %d years", interval)
+	}
+}
+
+func monthPeriod(interval int, ru bool) string {
+	switch {
+	case interval == 1 && ru:
+		return "каждый месяц"
+	case interval == 1:
+		return "every month"
+	case ru:
+		return fmt.Sprintf("раз в %d %s", interval, pluralRU(interval, "месяц", "месяца", "месяцев"))
+	default:
+		return fmt.Sprintf("every %d months", interval)
+	}
+}
+
+// joinWords объединяет слова через запятую, последнее — через "и" или "and"
+func joinWords(words []string, ru bool) string {
+	conj := " and "
+	if ru {
+		conj = " и "
+	}
+	if len(words) == 1 {
+		return words[0]
+	}
+	return strings.Join(words[:len(words)-1], ", ") + conj + words[len(words)-1]
+}
+
+// pluralRU выбирает форму слова для числа n: 1 день, 2 дня, 5 дней
+func pluralRU(n int, one, few, many string) string {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return one
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return few
+	default:
+		return many
+	}
+}
+
+func pluralEN(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -1,21 +1,38 @@
 package scheduler
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"go_final_project/internal"
 	"go_final_project/internal/logger"
 )
 
+// NextDateHandler возвращает следующую дату задачи текстом. С параметром describe=1
+// ответ приходит в JSON вместе с описанием правила на языке из параметра lang.
 func NextDateHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		nowStr := req.URL.Query().Get("now")
 		dateStr := req.URL.Query().Get("date")
 		repeatStr := req.URL.Query().Get("repeat")
 
-		now, err := parseNow(nowStr)
+		describe := false
+		switch describeStr := req.URL.Query().Get("describe"); describeStr {
+		case "", "0":
+		case "1":
+			describe = true
+		default:
+			logger.LogMessage(fmt.Sprintf("[ERROR] Некорректный параметр 'describe': %s", describeStr))
+			http.Error(w, "некорректный параметр 'describe'", http.StatusBadRequest)
+			return
+		}
+
+		now, err := parseNow(nowStr, req.URL.Query().Get("tz"))
 		if err != nil {
 			logger.LogMessage(fmt.Sprintf("[ERROR] Некорректный параметр 'now': %v", err))
 			http.Error(w, "некорректный параметр 'now'", http.StatusBadRequest)
@@ -29,6 +46,12 @@ func NextDateHandler() http.HandlerFunc {
 		}
 
 		nextDate, err := NextDate(now, dateStr, repeatStr)
+		// Исчерпанная серия — не ошибка правила, поэтому у неё свой код ответа
+		if errors.Is(err, ErrNoMoreOccurrences) {
+			logger.LogMessage("[INFO] Следующих повторений нет")
+			http.Error(w, ErrNoMoreOccurrences.Error(), http.StatusUnprocessableEntity)
+			return
+		}
 		if err != nil {
 			logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка вычисления следующей даты: %v", err))
 			http.Error(w, err.Error(), http.StatusBadRequest)
@@ -37,15 +60,171 @@ func NextDateHandler() http.HandlerFunc {
 
 		logger.LogMessage(fmt.Sprintf("[INFO] Успешно рассчитана следующая дата: %s", nextDate))
 
+		if describe {
+			description, err := DescribeRule(repeatStr, DescribeLang(req))
+			if err != nil {
+				logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка описания правила повторения: %v", err))
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]string{"date": nextDate, "description": description})
+			return
+		}
+
 		w.Header().Set("Content-Type", "text/plain")
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(nextDate))
 	}
 }
 
-func parseNow(nowStr string) (time.Time, error) {
+// OccurrencesHandler возвращает в JSON ближайшие даты повторений для пары date+repeat.
+// Параметр count задаёт число дат, from и to — необязательный интервал дат,
+// exdate — даты-исключения через запятую, time — время задачи для правил h и min.
+func OccurrencesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		dateStr := query.Get("date")
+		repeatStr := query.Get("repeat")
+
+		now, err := parseNow(query.Get("now"), query.Get("tz"))
+		if err != nil {
+			logger.LogMessage(fmt.Sprintf("[ERROR] Некорректный параметр 'now': %v", err))
+			http.Error(w, `{"error":"некорректный параметр 'now'"}`, http.StatusBadRequest)
+			return
+		}
+
+		start, err := time.Parse(internal.DateLayout, dateStr)
+		if err != nil {
+			logger.LogMessage(fmt.Sprintf("[ERROR] Некорректный параметр 'date': %v", err))
+			http.Error(w, `{"error":"некорректный параметр 'date'"}`, http.StatusBadRequest)
+			return
+		}
+		if timeStr := query.Get("time"); timeStr != "" {
+			clock, err := time.Parse(internal.TimeLayout, timeStr)
+			if err != nil {
+				logger.LogMessage(fmt.Sprintf("[ERROR] Некорректный параметр 'time': %v", err))
+				http.Error(w, `{"error":"некорректный параметр 'time'"}`, http.StatusBadRequest)
+				return
+			}
+			start = start.Add(time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute)
+		}
+
+		count := 10
+		if countStr := query.Get("count"); countStr != "" {
+			count, err = strconv.Atoi(countStr)
+			if err != nil || count < 1 || count > internal.OccurrenceLimit {
+				logger.LogMessage(fmt.Sprintf("[ERROR] Некорректный параметр 'count': %s", countStr))
+				http.Error(w, `{"error":"некорректный параметр 'count'"}`, http.StatusBadRequest)
+				return
+			}
+		}
+
+		var from, to time.Time
+		for name, value := range map[string]*time.Time{"from": &from, "to": &to} {
+			if str := query.Get(name); str != "" {
+				if *value, err = time.Parse(internal.DateLayout, str); err != nil {
+					logger.LogMessage(fmt.Sprintf("[ERROR] Некорректный параметр '%s': %v", name, err))
+					http.Error(w, `{"error":"некорректный параметр '`+name+`'"}`, http.StatusBadRequest)
+					return
+				}
+			}
+		}
+		// Без count интервал from..to должен уместиться в OccurrenceLimit дат: лишняя
+		// дата в запросе показывает, что ответ пришлось бы обрезать
+		window := !to.IsZero() && query.Get("count") == ""
+		if window {
+			count = internal.OccurrenceLimit + 1
+		}
+
+		var exclude []string
+		if exdates := query.Get("exdate"); exdates != "" {
+			exclude = strings.Split(exdates, ",")
+			for _, d := range exclude {
+				if _, err := time.Parse(internal.DateLayout, d); err != nil {
+					logger.LogMessage(fmt.Sprintf("[ERROR] Некорректный параметр 'exdate': %v", err))
+					http.Error(w, `{"error":"некорректный параметр 'exdate'"}`, http.StatusBadRequest)
+					return
+				}
+			}
+		}
+
+		dates, err := Occurrences(now, start, repeatStr, exclude, count, from, to)
+		if err != nil {
+			logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка вычисления дат повторений: %v", err))
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+		if window && len(dates) > internal.OccurrenceLimit {
+			logger.LogMessage(fmt.Sprintf("[ERROR] В интервале больше %d повторений", internal.OccurrenceLimit))
+			http.Error(w, fmt.Sprintf(`{"error":"в интервале больше %d повторений: сократите интервал или укажите count"}`,
+				internal.OccurrenceLimit), http.StatusBadRequest)
+			return
+		}
+
+		description, err := DescribeRule(repeatStr, DescribeLang(req))
+		if err != nil {
+			logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка описания правила повторения: %v", err))
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"dates": dates, "description": description})
+	}
+}
+
+// RRuleHandler переводит правило повторения между внутренним форматом и RRULE:
+// параметр repeat переводится в RRULE, параметр rrule — во внутренний формат.
+func RRuleHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		repeatStr := req.URL.Query().Get("repeat")
+		rruleStr := req.URL.Query().Get("rrule")
+
+		input := repeatStr
+		if input == "" {
+			input = rruleStr
+		}
+		if (repeatStr == "") == (rruleStr == "") || IsRRule(repeatStr) {
+			logger.LogMessage("[ERROR] Требуется один из параметров 'repeat' или 'rrule'")
+			http.Error(w, `{"error":"требуется один из параметров 'repeat' или 'rrule'"}`, http.StatusBadRequest)
+			return
+		}
+		if rruleStr != "" && !strings.HasPrefix(strings.ToUpper(rruleStr), rrulePrefix) {
+			input = rrulePrefix + rruleStr
+		}
+
+		rule, err := ParseRule(input)
+		if err == nil {
+			rruleStr, err = rule.RRule()
+		}
+		if err != nil {
+			logger.LogMessage(fmt.Sprintf("[ERROR] Ошибка перевода правила повторения: %v", err))
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"repeat": rule.String(), "rrule": rruleStr})
+	}
+}
+
+// parseNow возвращает дату из параметра now или текущее время в поясе tz
+func parseNow(nowStr, tz string) (time.Time, error) {
 	if nowStr == "" {
-		return time.Now(), nil
+		loc, err := LoadLocation(tz)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return Now(loc), nil
 	}
 	return time.Parse(internal.DateLayout, nowStr)
 }
+
+// DescribeLang возвращает язык описания правил из параметра lang, по умолчанию русский
+func DescribeLang(req *http.Request) string {
+	if lang := req.URL.Query().Get("lang"); lang != "" {
+		return lang
+	}
+	return LangRU
+}
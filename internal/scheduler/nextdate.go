@@ -2,8 +2,6 @@ package scheduler
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
 	"time"
 
 	"go_final_project/internal"
@@ -22,116 +20,337 @@ func NextDate(now time.Time, date string, repeat string) (string, error) {
 		return "", fmt.Errorf("неправильная дата %v", err)
 	}
 
-	repeatParts := strings.Fields(repeat)
-	if len(repeatParts) < 1 {
-		logger.LogMessage("[ERROR] Неверное правило повторения")
-		return "", fmt.Errorf("неверное правило повторения")
+	rule, err := ParseRule(repeat)
+	if err != nil {
+		return "", err
+	}
+	rule.Start = validDate
+
+	next, err := rule.Next(now)
+	if err != nil {
+		return "", err
 	}
+	return next.Format(internal.DateLayout), nil
+}
 
-	rule := repeatParts[0]
+// Next возвращает дату следующего повторения после даты Start.
+// Для правил d и y дата не раньше after, для остальных — строго после after.
+// Даты из Exclude пропускаются и не уменьшают count. При заданном Roll дата,
+// выпавшая на нерабочий день, переносится на ближайший рабочий день.
+// При FromDone отсчёт ведётся от даты after — даты выполнения задачи.
+// Дата after берётся в своём часовом поясе, поэтому её нужно передавать
+// в поясе задачи.
+// Для правил h и min Start и результат содержат время суток, а следующий слот
+// всегда строго позже after.
+// Если серия исчерпана, возвращается ErrNoMoreOccurrences.
+func (r *Rule) Next(after time.Time) (time.Time, error) {
+	next, _, err := r.NextScheduled(after)
+	return next, err
+}
 
-	var result string
-	switch rule {
-	case "d":
-		if len(repeatParts) < 2 {
-			logger.LogMessage("[ERROR] Отсутствует интервал для правила d")
-			return "", fmt.Errorf("отсутствует интервал для правила d")
+// NextScheduled работает как Next и вместе с датой повторения возвращает
+// её дату по графику до переноса roll. Эту дату нужно передать в Anchor
+// при расчёте следующего повторения, иначе перенесённая дата сдвинет
+// интервал every, отсчитываемый от неё.
+func (r *Rule) NextScheduled(after time.Time) (next, scheduled time.Time, err error) {
+	if r.Start.IsZero() {
+		logger.LogMessage("[ERROR] Не указана дата начала правила повторения")
+		return time.Time{}, time.Time{}, fmt.Errorf("не указана дата начала правила повторения")
+	}
+
+	after = wallClock(after)
+	start, anchor := r.Start, r.Anchor
+	if r.FromDone {
+		start = dayOf(after)
+		if r.SubDaily() {
+			start = after
 		}
-		result, err = everyDay(now, validDate, repeatParts[1])
-	case "y":
-		result, err = everyYear(now, validDate)
-	case "w":
-		if len(repeatParts) < 2 {
-			logger.LogMessage("[ERROR] Отсутствуют дни для правила w")
-			return "", fmt.Errorf("отсутствуют дни для правила w")
+	}
+	if anchor.IsZero() || r.FromDone {
+		anchor = start
+	}
+
+	scheduled, err = r.next(anchor, after)
+	for err == nil {
+		next = r.roll(scheduled)
+		if !r.Exclude[next.Format(internal.DateLayout)] && next.After(start) && r.reached(next, after) {
+			break
 		}
-		result, err = everyWeek(validDate, now, repeatParts[1])
-	case "m":
-		if len(repeatParts) < 2 {
-			logger.LogMessage("[ERROR] Отсутствуют дни для правила m")
-			return "", fmt.Errorf("отсутствуют дни для правила m")
+
+		// Интервалы отсчитываются от даты по графику, поэтому следующая дата после
+		// пропущенной совпадает с очередной датой исходного графика.
+		// Для правил d, bd и y результат может совпадать с after, поэтому сдвигаем его на день.
+		skip := scheduled
+		if r.nonStrict() {
+			skip = scheduled.AddDate(0, 0, 1)
 		}
-		result, err = everyMonth(validDate, now, repeatParts[1:])
+		scheduled, err = r.next(anchor, skip)
+	}
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if r.Count == 1 || !r.Until.IsZero() && dayOf(next).After(r.Until) {
+		logger.LogMessage(fmt.Sprintf("[INFO] Серия повторений завершена: %s", r))
+		return time.Time{}, time.Time{}, ErrNoMoreOccurrences
+	}
+
+	return next, scheduled, nil
+}
+
+func (r *Rule) next(start, after time.Time) (time.Time, error) {
+	switch r.Kind {
+	case RuleDay:
+		return everyDay(after, start, r.Interval), nil
+	case RuleBusinessDay:
+		return everyBusinessDay(after, start, r.Interval), nil
+	case RuleWeek:
+		return everyWeek(start, after, r.WeekDays, r.Interval), nil
+	case RuleWeekdayMonth:
+		return everyWeekdayOfMonth(start, after, r.Ordinals, r.Interval)
+	case RuleMonth:
+		return everyMonth(start, after, r.MonthDays, r.Months, r.Interval)
+	case RuleYear:
+		return everyYear(after, start, r.Interval), nil
+	case RuleHour, RuleMinute:
+		return everySlot(start, after, r.Step(), r.ActiveFrom, r.ActiveTo), nil
 	default:
-		logger.LogMessage(fmt.Sprintf("[ERROR] Неверное правило повторения: %v", rule))
-		return "", fmt.Errorf("неверное правило повторения: %v", rule)
+		logger.LogMessage(fmt.Sprintf("[ERROR] Неверное правило повторения: %v", r.Kind))
+		return time.Time{}, fmt.Errorf("неверное правило повторения: %v", r.Kind)
 	}
+}
 
-	return result, err
+// nonStrict проверяет, может ли следующая дата правила совпадать с after
+func (r *Rule) nonStrict() bool {
+	return r.Kind == RuleDay || r.Kind == RuleBusinessDay || r.Kind == RuleYear
 }
 
-func everyDay(now, date time.Time, daysStr string) (string, error) {
-	d, err := strconv.Atoi(daysStr)
-	if err != nil || d > 400 || d <= 0 {
-		logger.LogMessage("[ERROR] Неверное правило повторения в d")
-		return "", fmt.Errorf("неверное правило повторения в d")
+// reached проверяет, что перенесённая дата не раньше after по правилам r.next
+func (r *Rule) reached(date, after time.Time) bool {
+	if r.nonStrict() {
+		return !date.Before(after)
 	}
+	if r.SubDaily() {
+		return date.After(after)
+	}
+	return date.After(dayOf(after))
+}
+
+// roll переносит дату с нерабочего дня согласно Roll
+func (r *Rule) roll(date time.Time) time.Time {
+	switch r.Roll {
+	case RollNext:
+		return calendar.rollDate(date, 1)
+	case RollPrev:
+		return calendar.rollDate(date, -1)
+	}
+	return date
+}
 
+func everyDay(now, date time.Time, d int) time.Time {
 	resultDate := date.AddDate(0, 0, d)
 	for resultDate.Before(now) {
 		resultDate = resultDate.AddDate(0, 0, d)
 	}
 
-	return resultDate.Format(internal.DateLayout), nil
+	return resultDate
 }
 
-func everyWeek(date, now time.Time, daysStr string) (string, error) {
-	days := strings.Split(daysStr, ",")
-	validDays := make(map[int]bool)
-	for _, day := range days {
-		d, err := strconv.Atoi(day)
-		if err != nil || d < 1 || d > 7 {
-			logger.LogMessage(fmt.Sprintf("[ERROR] Неверный день недели: %s", day))
-			return "", fmt.Errorf("неверный день недели: %s", day)
+// everyBusinessDay обрабатывает правило "bd N": через N рабочих дней по календарю
+func everyBusinessDay(now, date time.Time, n int) time.Time {
+	for {
+		for left := n; left > 0; {
+			date = date.AddDate(0, 0, 1)
+			if calendar.IsWorkday(date) {
+				left--
+			}
 		}
+		if !date.Before(now) {
+			return date
+		}
+	}
+}
+
+// everyWeek обрабатывает правило "w <дни недели>". При интервале больше
+// единицы подходят только недели, отстоящие от недели даты задачи на кратное
+// интервалу число недель, поэтому выполнение с опозданием не сдвигает график.
+func everyWeek(date, now time.Time, days []int, interval int) time.Time {
+	validDays := make(map[int]bool)
+	for _, d := range days {
 		validDays[d] = true
 	}
 
-	date = date.AddDate(0, 0, 1)
+	anchor := weekIndex(date)
+	date = startDate(date, now).AddDate(0, 0, 1)
 	for {
+		if (weekIndex(date)-anchor)%interval != 0 {
+			// Переходим к понедельнику следующей недели
+			date = date.AddDate(0, 0, 7-(int(date.Weekday())+6)%7)
+			continue
+		}
+
 		weekDay := int(date.Weekday())
 		if weekDay == 0 {
 			weekDay = 7
 		}
 
 		if validDays[weekDay] {
-			return date.Format(internal.DateLayout), nil
+			return date
 		}
 		date = date.AddDate(0, 0, 1)
 	}
 }
 
-func everyMonth(date, now time.Time, days []string) (string, error) {
-	month := date.Month()
-	for {
-		for _, dayStr := range days {
-			targetDay, err := strconv.Atoi(dayStr)
-			if err != nil || targetDay < 1 || targetDay > 31 {
-				logger.LogMessage(fmt.Sprintf("[ERROR] Неверный день в правиле месяца: %v", dayStr))
-				return "", fmt.Errorf("неверный день в правиле месяца: %v", dayStr)
-			}
+// everyMonth обрабатывает правило "m <дни> [<месяцы>]".
+// Дни задаются числами от 1 до 31, -1 означает последний день месяца,
+// -2 — предпоследний. Необязательный список месяцев содержит числа от 1 до 12.
+func everyMonth(date, now time.Time, days, months []int, interval int) (time.Time, error) {
+	validDays := make(map[int]bool)
+	for _, d := range days {
+		validDays[d] = true
+	}
 
-			newDate := time.Date(date.Year(), month, targetDay, 0, 0, 0, 0, time.Local)
-			if newDate.Before(now) {
-				continue
-			}
+	validMonths := make(map[time.Month]bool)
+	for _, m := range months {
+		validMonths[time.Month(m)] = true
+	}
 
-			return newDate.Format(internal.DateLayout), nil
+	// За четыре года встречается любой день любого месяца, включая 29 февраля
+	anchor := monthIndex(date)
+	date = startDate(date, now)
+	limit := date.AddDate(4*interval, 0, 0)
+	for date = date.AddDate(0, 0, 1); date.Before(limit); {
+		if (monthIndex(date)-anchor)%interval != 0 || len(validMonths) > 0 && !validMonths[date.Month()] {
+			date = nextMonth(date)
+			continue
+		}
+		if matchMonthDay(date, validDays) {
+			return date, nil
 		}
+		date = date.AddDate(0, 0, 1)
+	}
 
-		date = date.AddDate(0, 1, 0)
-		month = date.Month()
+	logger.LogMessage("[ERROR] Нет подходящей даты для правила m")
+	return time.Time{}, fmt.Errorf("нет подходящей даты для правила m")
+}
+
+// everyWeekdayOfMonth обрабатывает правило "wm <номер>.<день недели>[,...]",
+// например "wm 1.1" — первый понедельник месяца, "wm -1.5" — последняя пятница.
+// Номер может быть от 1 до 5, -1 (последний) или -2 (предпоследний).
+func everyWeekdayOfMonth(date, now time.Time, days []OrdinalDay, interval int) (time.Time, error) {
+	// Пятый день недели встречается не в каждом месяце, а при интервале в год
+	// и больше нужный месяц повторяет календарь не реже чем раз в 28 лет
+	anchor := monthIndex(date)
+	date = startDate(date, now)
+	limit := date.AddDate(28*interval, 0, 0)
+	for date = date.AddDate(0, 0, 1); date.Before(limit); date = date.AddDate(0, 0, 1) {
+		if (monthIndex(date)-anchor)%interval != 0 {
+			date = nextMonth(date).AddDate(0, 0, -1)
+			continue
+		}
+
+		weekDay := int(date.Weekday())
+		if weekDay == 0 {
+			weekDay = 7
+		}
+		lastDay := date.AddDate(0, 1, -date.Day()).Day()
+		fromStart := (date.Day()-1)/7 + 1
+		fromEnd := -((lastDay-date.Day())/7 + 1)
+
+		for _, d := range days {
+			if d.WeekDay == weekDay && (d.Ordinal == fromStart || d.Ordinal == fromEnd) {
+				return date, nil
+			}
+		}
+	}
+
+	logger.LogMessage("[ERROR] Нет подходящей даты для правила wm")
+	return time.Time{}, fmt.Errorf("нет подходящей даты для правила wm")
+}
+
+// matchMonthDay проверяет, подходит ли день даты под один из дней правила m
+func matchMonthDay(date time.Time, days map[int]bool) bool {
+	lastDay := date.AddDate(0, 1, -date.Day()).Day()
+	switch {
+	case days[date.Day()]:
+		return true
+	case days[-1] && date.Day() == lastDay:
+		return true
+	case days[-2] && date.Day() == lastDay-1:
+		return true
+	}
+	return false
+}
+
+// weekIndex возвращает номер недели (с понедельника) от начала эпохи Unix
+func weekIndex(date time.Time) int {
+	// 1 января 1970 года — четверг, сдвигаем отсчёт на понедельник
+	days := int(date.Unix()/(24*60*60)) + 3
+	if days < 0 {
+		return (days - 6) / 7
+	}
+	return days / 7
+}
+
+// monthIndex возвращает порядковый номер месяца с начала нашей эры
+func monthIndex(date time.Time) int {
+	return date.Year()*12 + int(date.Month()) - 1
+}
+
+// nextMonth возвращает первое число месяца, следующего за месяцем даты
+func nextMonth(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month()+1, 1, 0, 0, 0, 0, date.Location())
+}
+
+// startDate возвращает большую из дат date и now без учёта времени суток
+func startDate(date, now time.Time) time.Time {
+	today := dayOf(now)
+	if today.After(date) {
+		return today
+	}
+	return date
+}
+
+// dayOf возвращает дату без учёта времени суток
+func dayOf(t time.Time) time.Time {
+	day, _ := time.Parse(internal.DateLayout, t.Format(internal.DateLayout))
+	return day
+}
+
+// everySlot обрабатывает правила "h N" и "min N": слоты через step, строго
+// после start и after. Без окна активных часов слоты отсчитываются от start,
+// с окном — каждый день заново от его начала до конца включительно.
+func everySlot(start, after time.Time, step time.Duration, activeFrom, activeTo int) time.Time {
+	if start.After(after) {
+		after = start
+	}
+
+	if activeTo == 0 {
+		n := after.Sub(start)/step + 1
+		return start.Add(n * step)
+	}
+
+	for day := dayOf(after); ; day = day.AddDate(0, 0, 1) {
+		from := day.Add(time.Duration(activeFrom) * time.Minute)
+		to := day.Add(time.Duration(activeTo) * time.Minute)
+		slot := from
+		if !after.Before(from) {
+			slot = from.Add((after.Sub(from)/step + 1) * step)
+		}
+		if !slot.After(to) {
+			return slot
+		}
 	}
 }
 
-func everyYear(now, date time.Time) (string, error) {
+func everyYear(now, date time.Time, interval int) time.Time {
 	if date.Before(now) {
 		for date.Before(now) {
-			date = date.AddDate(1, 0, 0) // добавляем год
+			date = date.AddDate(interval, 0, 0) // добавляем год
 		}
 	} else {
-		date = date.AddDate(1, 0, 0) // добавляем год
+		date = date.AddDate(interval, 0, 0) // добавляем год
 	}
 
-	return date.Format(internal.DateLayout), nil
+	return date
 }
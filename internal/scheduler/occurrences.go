@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+
+	"go_final_project/internal"
+)
+
+// Occurrences возвращает даты следующих повторений задачи после now.
+// Если задан непустой интервал from..to, возвращаются только даты из него.
+// Даты из exclude пропускаются. Результат ограничен limit датами.
+// Для правил h и min start содержит время задачи, а даты возвращаются
+// вместе со временем в формате "YYYYMMDD HH:MM".
+func Occurrences(now, start time.Time, repeat string, exclude []string, limit int, from, to time.Time) ([]string, error) {
+	rule, err := ParseRule(repeat)
+	if err != nil {
+		return nil, err
+	}
+	if !rule.SubDaily() {
+		start = dayOf(start)
+	}
+	rule.Start = start
+	rule.Exclude = make(map[string]bool, len(exclude))
+	for _, d := range exclude {
+		rule.Exclude[d] = true
+	}
+
+	if !from.IsZero() {
+		now = from.AddDate(0, 0, -1)
+	}
+
+	dates := []string{}
+	for len(dates) < limit {
+		next, scheduled, err := rule.NextScheduled(now)
+		if errors.Is(err, ErrNoMoreOccurrences) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !to.IsZero() && dayOf(next).After(to) {
+			break
+		}
+		if from.IsZero() || !next.Before(from) {
+			layout := internal.DateLayout
+			if rule.SubDaily() {
+				layout += " " + internal.TimeLayout
+			}
+			dates = append(dates, next.Format(layout))
+		}
+
+		// Следующая дата отсчитывается от даты по графику, а не от перенесённой roll
+		now, rule.Start, rule.Anchor = next, next, scheduled
+		if rule.Count > 1 {
+			rule.Count--
+		}
+	}
+	return dates, nil
+}
@@ -0,0 +1,285 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go_final_project/internal"
+	"go_final_project/internal/logger"
+)
+
+const rrulePrefix = "RRULE:"
+
+var rruleWeekDays = []string{"MO", "TU", "WE", "TH", "FR", "SA", "SU"}
+
+// IsRRule проверяет, записано ли правило повторения в формате RFC 5545 RRULE
+func IsRRule(repeat string) bool {
+	repeat = strings.TrimSpace(repeat)
+	return strings.HasPrefix(strings.ToUpper(repeat), rrulePrefix) || strings.HasPrefix(strings.ToUpper(repeat), "FREQ=")
+}
+
+// FromRRule переводит правило RRULE во внутренний формат, например
+// "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH" -> "w 1,4 every 2".
+// Для частей RRULE, которые нельзя выразить внутренним правилом, возвращается ошибка.
+func FromRRule(rrule string) (string, error) {
+	body := strings.TrimSpace(rrule)
+	if strings.HasPrefix(strings.ToUpper(body), rrulePrefix) {
+		body = body[len(rrulePrefix):]
+	}
+
+	parts := make(map[string]string)
+	for _, item := range strings.Split(body, ";") {
+		key, value, ok := strings.Cut(item, "=")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		if !ok || key == "" || value == "" {
+			return "", rruleError("неверная часть RRULE: %s", item)
+		}
+		if _, dup := parts[key]; dup {
+			return "", rruleError("повторная часть RRULE: %s", key)
+		}
+		parts[key] = strings.ToUpper(strings.TrimSpace(value))
+	}
+
+	interval := 1
+	if value, ok := parts["INTERVAL"]; ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return "", rruleError("неверный INTERVAL в RRULE: %s", value)
+		}
+		interval = n
+	}
+
+	for key := range parts {
+		switch key {
+		case "FREQ", "INTERVAL", "BYDAY", "BYMONTHDAY", "BYMONTH", "UNTIL", "COUNT", "WKST":
+		default:
+			return "", rruleError("неподдерживаемая часть RRULE: %s", key)
+		}
+	}
+
+	// Внутренние правила считают недели с понедельника, поэтому другое начало недели не поддерживается
+	if wkst, ok := parts["WKST"]; ok && wkst != "MO" {
+		return "", rruleError("поддерживается только WKST=MO: %s", wkst)
+	}
+
+	var rule string
+	switch freq := parts["FREQ"]; freq {
+	case "DAILY":
+		if err := rruleOnly(parts, "DAILY"); err != nil {
+			return "", err
+		}
+		rule = "d " + strconv.Itoa(interval)
+		interval = 1
+	case "HOURLY", "MINUTELY":
+		if err := rruleOnly(parts, freq); err != nil {
+			return "", err
+		}
+		rule = RuleHour
+		if freq == "MINUTELY" {
+			rule = RuleMinute
+		}
+		rule += " " + strconv.Itoa(interval)
+		interval = 1
+	case "WEEKLY":
+		if _, ok := parts["BYDAY"]; !ok {
+			return "", rruleError("для FREQ=WEEKLY требуется BYDAY")
+		}
+		if err := rruleOnly(parts, "WEEKLY", "BYDAY"); err != nil {
+			return "", err
+		}
+		days, err := rruleWeekDaysToRule(parts["BYDAY"], false)
+		if err != nil {
+			return "", err
+		}
+		rule = "w " + days
+	case "MONTHLY":
+		if byDay, ok := parts["BYDAY"]; ok {
+			if err := rruleOnly(parts, "MONTHLY", "BYDAY"); err != nil {
+				return "", err
+			}
+			days, err := rruleWeekDaysToRule(byDay, true)
+			if err != nil {
+				return "", err
+			}
+			rule = "wm " + days
+			break
+		}
+		if _, ok := parts["BYMONTHDAY"]; !ok {
+			return "", rruleError("для FREQ=MONTHLY требуется BYMONTHDAY или BYDAY")
+		}
+		if err := rruleOnly(parts, "MONTHLY", "BYMONTHDAY", "BYMONTH"); err != nil {
+			return "", err
+		}
+		rule = "m " + parts["BYMONTHDAY"]
+		if months, ok := parts["BYMONTH"]; ok {
+			rule += " " + months
+		}
+	case "YEARLY":
+		_, hasDay := parts["BYMONTHDAY"]
+		_, hasMonth := parts["BYMONTH"]
+		switch {
+		case !hasDay && !hasMonth:
+			if err := rruleOnly(parts, "YEARLY"); err != nil {
+				return "", err
+			}
+			rule = "y"
+		case hasDay && hasMonth && interval == 1:
+			if err := rruleOnly(parts, "YEARLY", "BYMONTHDAY", "BYMONTH"); err != nil {
+				return "", err
+			}
+			rule = "m " + parts["BYMONTHDAY"] + " " + parts["BYMONTH"]
+		default:
+			return "", rruleError("для FREQ=YEARLY поддерживаются только BYMONTH вместе с BYMONTHDAY без INTERVAL")
+		}
+	case "":
+		return "", rruleError("в RRULE отсутствует FREQ")
+	default:
+		return "", rruleError("неподдерживаемая частота RRULE: %s", freq)
+	}
+
+	if interval != 1 {
+		rule += " every " + strconv.Itoa(interval)
+	}
+
+	until, hasUntil := parts["UNTIL"]
+	count, hasCount := parts["COUNT"]
+	if hasUntil && hasCount {
+		return "", rruleError("UNTIL и COUNT не могут использоваться вместе")
+	}
+	if hasUntil {
+		if len(until) < 8 {
+			return "", rruleError("неверный UNTIL в RRULE: %s", until)
+		}
+		rule += " until " + until[:8]
+	}
+	if hasCount {
+		rule += " count " + count
+	}
+
+	return rule, nil
+}
+
+// ToRRule переводит правило повторения из внутреннего формата в RRULE,
+// например "m 1,-1 3,6" -> "RRULE:FREQ=MONTHLY;BYMONTHDAY=1,-1;BYMONTH=3,6".
+func ToRRule(repeat string) (string, error) {
+	rule, err := ParseRule(repeat)
+	if err != nil {
+		return "", err
+	}
+	return rule.RRule()
+}
+
+// RRule возвращает правило в формате RRULE. Правила по рабочим дням,
+// перенос с нерабочих дней и окно активных часов в RRULE не выражаются.
+func (r *Rule) RRule() (string, error) {
+	if r.Kind == RuleBusinessDay {
+		return "", rruleError("правило bd не выражается в RRULE")
+	}
+	if r.Roll != "" {
+		return "", rruleError("условие roll не выражается в RRULE")
+	}
+	if r.FromDone {
+		return "", rruleError("повторение от даты выполнения не выражается в RRULE")
+	}
+	if r.ActiveTo != 0 {
+		return "", rruleError("окно активных часов не выражается в RRULE")
+	}
+
+	var items []string
+	switch r.Kind {
+	case RuleDay:
+		items = append(items, "FREQ=DAILY", "INTERVAL="+strconv.Itoa(r.Interval))
+	case RuleHour:
+		items = append(items, "FREQ=HOURLY", "INTERVAL="+strconv.Itoa(r.Interval))
+	case RuleMinute:
+		items = append(items, "FREQ=MINUTELY", "INTERVAL="+strconv.Itoa(r.Interval))
+	case RuleWeek:
+		var days []string
+		for _, d := range r.WeekDays {
+			days = append(days, rruleWeekDays[d-1])
+		}
+		items = append(items, "FREQ=WEEKLY", "BYDAY="+strings.Join(days, ","))
+	case RuleWeekdayMonth:
+		var days []string
+		for _, d := range r.Ordinals {
+			days = append(days, strconv.Itoa(d.Ordinal)+rruleWeekDays[d.WeekDay-1])
+		}
+		items = append(items, "FREQ=MONTHLY", "BYDAY="+strings.Join(days, ","))
+	case RuleMonth:
+		items = append(items, "FREQ=MONTHLY", "BYMONTHDAY="+joinInts(r.MonthDays))
+		if len(r.Months) > 0 {
+			items = append(items, "BYMONTH="+joinInts(r.Months))
+		}
+	case RuleYear:
+		items = append(items, "FREQ=YEARLY")
+	}
+
+	if r.Kind != RuleDay && !r.SubDaily() && r.Interval > 1 {
+		items = append(items, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if !r.Until.IsZero() {
+		items = append(items, "UNTIL="+r.Until.Format(internal.DateLayout))
+	}
+	if r.Count != 0 {
+		items = append(items, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	return rrulePrefix + strings.Join(items, ";"), nil
+}
+
+// rruleOnly проверяет, что в RRULE нет частей BY*, кроме разрешённых
+func rruleOnly(parts map[string]string, freq string, allowed ...string) error {
+	for _, key := range []string{"BYDAY", "BYMONTHDAY", "BYMONTH"} {
+		if _, ok := parts[key]; !ok {
+			continue
+		}
+		found := false
+		for _, a := range allowed {
+			found = found || a == key
+		}
+		if !found {
+			return rruleError("%s не поддерживается для FREQ=%s", key, freq)
+		}
+	}
+	return nil
+}
+
+// rruleWeekDaysToRule переводит BYDAY ("MO,TH" или "1MO,-1FR") в дни правил w и wm
+func rruleWeekDaysToRule(byDay string, ordinal bool) (string, error) {
+	var days []string
+	for _, item := range strings.Split(byDay, ",") {
+		if len(item) < 2 {
+			return "", rruleError("неверный день в BYDAY: %s", item)
+		}
+		ord, code := item[:len(item)-2], item[len(item)-2:]
+
+		day := 0
+		for i, wd := range rruleWeekDays {
+			if wd == code {
+				day = i + 1
+			}
+		}
+		if day == 0 {
+			return "", rruleError("неверный день в BYDAY: %s", item)
+		}
+
+		switch {
+		case ordinal && ord == "":
+			return "", rruleError("для FREQ=MONTHLY в BYDAY требуется номер дня: %s", item)
+		case !ordinal && ord != "":
+			return "", rruleError("номер дня в BYDAY поддерживается только для FREQ=MONTHLY: %s", item)
+		case ordinal:
+			days = append(days, strings.TrimPrefix(ord, "+")+"."+strconv.Itoa(day))
+		default:
+			days = append(days, strconv.Itoa(day))
+		}
+	}
+	return strings.Join(days, ","), nil
+}
+
+func rruleError(format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	logger.LogMessage("[ERROR] " + msg)
+	return fmt.Errorf("%s", msg)
+}
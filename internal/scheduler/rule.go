@@ -0,0 +1,437 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go_final_project/internal"
+	"go_final_project/internal/logger"
+)
+
+// ErrNoMoreOccurrences возвращается, когда серия повторений исчерпана
+var ErrNoMoreOccurrences = errors.New("повторений больше нет")
+
+const (
+	// maxDayInterval ограничивает интервал правил d и bd
+	maxDayInterval = 400
+	// maxInterval ограничивает интервал "every N" для правил w, wm, m и y
+	maxInterval = 100
+	// maxHourInterval и maxMinuteInterval ограничивают интервал правил h и min сутками
+	maxHourInterval   = 24
+	maxMinuteInterval = 24 * 60
+)
+
+// Виды правил повторения
+const (
+	RuleDay          = "d"
+	RuleWeek         = "w"
+	RuleWeekdayMonth = "wm"
+	RuleMonth        = "m"
+	RuleYear         = "y"
+	RuleBusinessDay  = "bd"
+	RuleHour         = "h"
+	RuleMinute       = "min"
+)
+
+// Направления переноса даты с нерабочего дня для модификатора roll
+const (
+	RollNext = "next"
+	RollPrev = "prev"
+)
+
+// ruleOptions — ключевые слова модификаторов правила
+var ruleOptions = map[string]bool{"every": true, "until": true, "count": true, "roll": true, "from": true, "between": true}
+
+// OrdinalDay — день недели с номером в месяце: {1, 1} — первый понедельник,
+// {-1, 5} — последняя пятница
+type OrdinalDay struct {
+	Ordinal int
+	WeekDay int
+}
+
+// Rule — разобранное правило повторения.
+// Start — дата задачи, от которой отсчитываются повторения и интервалы.
+// Exclude — даты-исключения в формате YYYYMMDD, которые пропускаются при расчёте.
+// Roll — перенос дат, выпавших на нерабочие дни: RollNext, RollPrev или пусто.
+// FromDone — повторение отсчитывается от даты выполнения, а не от графика.
+// ActiveFrom и ActiveTo — окно активных часов правил h и min в минутах от
+// начала суток; ActiveTo == 0 означает, что окно не задано.
+type Rule struct {
+	Kind       string
+	Interval   int
+	WeekDays   []int
+	Ordinals   []OrdinalDay
+	MonthDays  []int
+	Months     []int
+	Until      time.Time
+	Count      int
+	Roll       string
+	FromDone   bool
+	ActiveFrom int
+	ActiveTo   int
+	Start      time.Time
+	// Anchor — дата Start по графику до переноса roll, от неё отсчитываются
+	// интервалы; нулевая — совпадает со Start
+	Anchor  time.Time
+	Exclude map[string]bool
+}
+
+// RuleError — ошибка разбора правила повторения с указанием неверного
+// элемента и его позиции (номер символа, начиная с 1; 0 — позиция неизвестна)
+type RuleError struct {
+	Msg   string
+	Token string
+	Pos   int
+}
+
+func (e *RuleError) Error() string {
+	msg := e.Msg
+	if e.Token != "" {
+		msg += ": " + e.Token
+	}
+	if e.Pos == 0 {
+		return msg
+	}
+	return fmt.Sprintf("%s (позиция %d)", msg, e.Pos)
+}
+
+type ruleToken struct {
+	text string
+	pos  int
+}
+
+// ParseRule разбирает правило повторения во внутреннем формате или в формате RRULE.
+// Внутренний формат: "<вид> [<аргументы>] [every N] [until YYYYMMDD] [count N]
+// [roll next|prev] [from done|schedule] [between HH:MM-HH:MM]".
+func ParseRule(repeat string) (*Rule, error) {
+	if !IsRRule(repeat) {
+		return parseRule(repeat)
+	}
+
+	translated, err := FromRRule(repeat)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := parseRule(translated)
+	var ruleErr *RuleError
+	if errors.As(err, &ruleErr) {
+		// Позиция указывает на правило во внутреннем формате, а не на строку RRULE
+		ruleErr.Pos = 0
+	}
+	return rule, err
+}
+
+// parseRule разбирает правило повторения во внутреннем формате
+func parseRule(repeat string) (*Rule, error) {
+	tokens := tokenizeRule(repeat)
+	if len(tokens) == 0 {
+		return nil, ruleError("повтор пуст", ruleToken{pos: 1})
+	}
+
+	rule := &Rule{Kind: tokens[0].text, Interval: 1}
+	args := tokens[1:]
+	var options []ruleToken
+	for i, tok := range args {
+		if ruleOptions[tok.text] {
+			options = args[i:]
+			args = args[:i]
+			break
+		}
+	}
+	end := ruleToken{pos: len([]rune(repeat)) + 1}
+
+	var err error
+	used := 1
+	switch rule.Kind {
+	case RuleDay:
+		if len(args) == 0 {
+			return nil, ruleError("отсутствует интервал для правила d", end)
+		}
+		rule.Interval, err = parseRuleNumber(args[0], 1, maxDayInterval, "неверный интервал для правила d")
+	case RuleBusinessDay:
+		if len(args) == 0 {
+			return nil, ruleError("отсутствует интервал для правила bd", end)
+		}
+		rule.Interval, err = parseRuleNumber(args[0], 1, maxDayInterval, "неверный интервал для правила bd")
+	case RuleWeek:
+		if len(args) == 0 {
+			return nil, ruleError("отсутствуют дни для правила w", end)
+		}
+		rule.WeekDays, err = parseRuleList(args[0], 1, 7, "неверный день недели")
+	case RuleWeekdayMonth:
+		if len(args) == 0 {
+			return nil, ruleError("отсутствуют дни для правила wm", end)
+		}
+		rule.Ordinals, err = parseOrdinalDays(args[0])
+	case RuleMonth:
+		if len(args) == 0 {
+			return nil, ruleError("отсутствуют дни для правила m", end)
+		}
+		rule.MonthDays, err = parseRuleList(args[0], -2, 31, "неверный день в правиле месяца")
+		if err == nil && len(args) > 1 {
+			rule.Months, err = parseRuleList(args[1], 1, 12, "неверный месяц в правиле месяца")
+			used = 2
+		}
+	case RuleHour:
+		if len(args) == 0 {
+			return nil, ruleError("отсутствует интервал для правила h", end)
+		}
+		rule.Interval, err = parseRuleNumber(args[0], 1, maxHourInterval, "неверный интервал для правила h")
+	case RuleMinute:
+		if len(args) == 0 {
+			return nil, ruleError("отсутствует интервал для правила min", end)
+		}
+		rule.Interval, err = parseRuleNumber(args[0], 1, maxMinuteInterval, "неверный интервал для правила min")
+	case RuleYear:
+		used = 0
+	default:
+		return nil, ruleError("неверное правило повторения", tokens[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > used {
+		return nil, ruleError("лишний элемент правила", args[used])
+	}
+
+	if err := rule.parseOptions(options); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// parseOptions разбирает модификаторы every, until, count, roll, from и between
+func (r *Rule) parseOptions(options []ruleToken) error {
+	seen := make(map[string]bool)
+	for i := 0; i < len(options); i += 2 {
+		key := options[i]
+		if !ruleOptions[key.text] {
+			return ruleError("неизвестное условие правила", key)
+		}
+		if seen[key.text] {
+			return ruleError("повторное условие", key)
+		}
+		seen[key.text] = true
+		if i+1 >= len(options) {
+			return ruleError("отсутствует значение для "+key.text, ruleToken{pos: key.pos + len([]rune(key.text))})
+		}
+
+		value := options[i+1]
+		var err error
+		switch key.text {
+		case "every":
+			if r.Kind == RuleDay || r.Kind == RuleBusinessDay || r.SubDaily() {
+				return ruleError("условие every не применяется к правилу "+r.Kind, key)
+			}
+			r.Interval, err = parseRuleNumber(value, 1, maxInterval, "неверный интервал в условии every")
+		case "until":
+			r.Until, err = time.Parse(internal.DateLayout, value.text)
+			if err != nil {
+				return ruleError("неверная дата в условии until", value)
+			}
+		case "count":
+			r.Count, err = parseRuleNumber(value, 1, 1<<20, "неверное количество повторений")
+		case "roll":
+			if r.SubDaily() {
+				return ruleError("условие roll не применяется к правилу "+r.Kind, key)
+			}
+			if value.text != RollNext && value.text != RollPrev {
+				return ruleError("неверное направление переноса", value)
+			}
+			r.Roll = value.text
+		case "from":
+			if value.text != "done" && value.text != "schedule" {
+				return ruleError("неверный режим повторения", value)
+			}
+			r.FromDone = value.text == "done"
+		case "between":
+			if !r.SubDaily() {
+				return ruleError("условие between применяется только к правилам h и min", key)
+			}
+			r.ActiveFrom, r.ActiveTo, err = parseActiveHours(value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String возвращает правило во внутреннем формате; ParseRule(r.String()) даёт то же правило
+func (r *Rule) String() string {
+	parts := []string{r.Kind}
+	switch r.Kind {
+	case RuleDay, RuleBusinessDay, RuleHour, RuleMinute:
+		parts = append(parts, strconv.Itoa(r.Interval))
+	case RuleWeek:
+		parts = append(parts, joinInts(r.WeekDays))
+	case RuleWeekdayMonth:
+		var days []string
+		for _, d := range r.Ordinals {
+			days = append(days, fmt.Sprintf("%d.%d", d.Ordinal, d.WeekDay))
+		}
+		parts = append(parts, strings.Join(days, ","))
+	case RuleMonth:
+		parts = append(parts, joinInts(r.MonthDays))
+		if len(r.Months) > 0 {
+			parts = append(parts, joinInts(r.Months))
+		}
+	}
+
+	if r.Kind != RuleDay && r.Kind != RuleBusinessDay && !r.SubDaily() && r.Interval > 1 {
+		parts = append(parts, "every", strconv.Itoa(r.Interval))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "until", r.Until.Format(internal.DateLayout))
+	}
+	if r.Count != 0 {
+		parts = append(parts, "count", strconv.Itoa(r.Count))
+	}
+	if r.Roll != "" {
+		parts = append(parts, "roll", r.Roll)
+	}
+	if r.FromDone {
+		parts = append(parts, "from", "done")
+	}
+	if r.ActiveTo != 0 {
+		parts = append(parts, "between", formatClock(r.ActiveFrom)+"-"+formatClock(r.ActiveTo))
+	}
+	return strings.Join(parts, " ")
+}
+
+// SubDaily проверяет, повторяется ли правило чаще раза в сутки (правила h и min)
+func (r *Rule) SubDaily() bool {
+	return r.Kind == RuleHour || r.Kind == RuleMinute
+}
+
+// Step возвращает шаг повторения правил h и min
+func (r *Rule) Step() time.Duration {
+	if r.Kind == RuleHour {
+		return time.Duration(r.Interval) * time.Hour
+	}
+	return time.Duration(r.Interval) * time.Minute
+}
+
+// ConsumeOccurrence уменьшает счётчик count в правиле повторения после
+// выполнения очередного повторения. Формат правила (внутренний или RRULE)
+// сохраняется; правила без count возвращаются без изменений.
+func ConsumeOccurrence(repeat string) string {
+	rule, err := ParseRule(repeat)
+	if err != nil || rule.Count <= 1 {
+		return repeat
+	}
+
+	rule.Count--
+	if IsRRule(repeat) {
+		if rrule, err := rule.RRule(); err == nil {
+			return rrule
+		}
+	}
+	return rule.String()
+}
+
+func tokenizeRule(repeat string) []ruleToken {
+	var tokens []ruleToken
+	var current []rune
+	start := 0
+	for i, r := range []rune(repeat + " ") {
+		if unicode.IsSpace(r) {
+			if len(current) > 0 {
+				tokens = append(tokens, ruleToken{text: string(current), pos: start + 1})
+				current = nil
+			}
+			continue
+		}
+		if len(current) == 0 {
+			start = i
+		}
+		current = append(current, r)
+	}
+	return tokens
+}
+
+// splitRuleToken разбивает элемент правила на части через запятую, сохраняя позиции
+func splitRuleToken(tok ruleToken) []ruleToken {
+	var items []ruleToken
+	pos := tok.pos
+	for _, item := range strings.Split(tok.text, ",") {
+		items = append(items, ruleToken{text: item, pos: pos})
+		pos += len([]rune(item)) + 1
+	}
+	return items
+}
+
+func parseRuleNumber(tok ruleToken, min, max int, msg string) (int, error) {
+	n, err := strconv.Atoi(tok.text)
+	if err != nil || n < min || n > max {
+		return 0, ruleError(msg, tok)
+	}
+	return n, nil
+}
+
+// parseRuleList разбирает список чисел через запятую из диапазона min..max, кроме нуля
+func parseRuleList(tok ruleToken, min, max int, msg string) ([]int, error) {
+	var values []int
+	for _, item := range splitRuleToken(tok) {
+		n, err := parseRuleNumber(item, min, max, msg)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return nil, ruleError(msg, item)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+func parseOrdinalDays(tok ruleToken) ([]OrdinalDay, error) {
+	var days []OrdinalDay
+	for _, item := range splitRuleToken(tok) {
+		ordStr, dayStr, ok := strings.Cut(item.text, ".")
+		ord, errOrd := strconv.Atoi(ordStr)
+		if !ok || errOrd != nil || ord == 0 || ord < -2 || ord > 5 {
+			return nil, ruleError("неверный номер дня недели в правиле wm", item)
+		}
+		day, errDay := strconv.Atoi(dayStr)
+		if errDay != nil || day < 1 || day > 7 {
+			return nil, ruleError("неверный день недели", item)
+		}
+		days = append(days, OrdinalDay{Ordinal: ord, WeekDay: day})
+	}
+	return days, nil
+}
+
+// parseActiveHours разбирает окно активных часов "HH:MM-HH:MM" в минуты от начала суток
+func parseActiveHours(tok ruleToken) (int, int, error) {
+	fromStr, toStr, ok := strings.Cut(tok.text, "-")
+	from, errFrom := time.Parse(internal.TimeLayout, fromStr)
+	to, errTo := time.Parse(internal.TimeLayout, toStr)
+	if !ok || errFrom != nil || errTo != nil || !to.After(from) {
+		return 0, 0, ruleError("неверное окно активных часов", tok)
+	}
+	return from.Hour()*60 + from.Minute(), to.Hour()*60 + to.Minute(), nil
+}
+
+// formatClock возвращает время суток в формате HH:MM по числу минут от начала суток
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func joinInts(values []int) string {
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = strconv.Itoa(v)
+	}
+	return strings.Join(items, ",")
+}
+
+func ruleError(msg string, tok ruleToken) error {
+	err := &RuleError{Msg: msg, Token: tok.text, Pos: tok.pos}
+	logger.LogMessage("[ERROR] " + err.Error())
+	return err
+}
@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"go_final_project/internal/logger"
+)
+
+// location — часовой пояс по умолчанию для задач без собственного пояса
+var location = time.Local
+
+// InitLocation задаёт часовой пояс по умолчанию по имени IANA. Пустое имя оставляет местное время сервера.
+func InitLocation(name string) error {
+	if name == "" {
+		return nil
+	}
+
+	loc, err := LoadLocation(name)
+	if err != nil {
+		return err
+	}
+	location = loc
+	logger.LogMessage(fmt.Sprintf("[INFO] Часовой пояс по умолчанию: %s", name))
+	return nil
+}
+
+// DefaultLocation возвращает часовой пояс по умолчанию
+func DefaultLocation() *time.Location {
+	return location
+}
+
+// LoadLocation возвращает часовой пояс по имени IANA, для пустого имени — пояс по умолчанию
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return location, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Неизвестный часовой пояс: %s", name))
+		return nil, fmt.Errorf("неизвестный часовой пояс: %s", name)
+	}
+	return loc, nil
+}
+
+// Now возвращает текущее время в часовом поясе loc
+func Now(loc *time.Location) time.Time {
+	return time.Now().In(loc)
+}
+
+// wallClock переносит показания часов момента t в UTC, чтобы даты правил,
+// которые хранятся как полночь UTC, сравнивались с местными датой и временем
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
@@ -0,0 +1,131 @@
+package task
+
+import "time"
+
+// TaskStore — хранилище задач и связанных с ними данных. Обработчики работают
+// только через него, поэтому хранилище можно заменить: SQLite и PostgreSQL
+// реализует sqlStore, хранение в памяти — memoryStore.
+type TaskStore interface {
+	// AddTask сохраняет новую задачу вместе с метками и чек-листом и возвращает её идентификатор
+	AddTask(t *Task) (int64, error)
+	// GetTask возвращает активную задачу с исключениями, чек-листом, зависимостями и метками
+	GetTask(id string) (*Task, error)
+	// UpdateTask сохраняет изменения задачи; метки и чек-лист, равные nil, не меняются
+	UpdateTask(t *Task) error
+	// ListTasks возвращает активные задачи по фильтру с метками и признаком блокировки
+	ListTasks(f TaskFilter) ([]Task, error)
+	// CountTasks возвращает число активных задач по фильтру без учёта Cursor и Limit
+	CountTasks(f TaskFilter) (int, error)
+	// RescheduleTask переносит задачу на дату date и время clock
+	RescheduleTask(id, date, clock string) error
+	// SetTaskRepeat заменяет правило повторения задачи
+	SetTaskRepeat(id, repeat string) error
+
+	// TrashTask помещает задачу в корзину как выполненную (completed) или удалённую.
+	// Метки, чек-лист, исключения и зависимости сохраняются; пока задача в корзине,
+	// она не блокирует другие задачи и не показывается в их списках зависимостей.
+	TrashTask(id string, completed bool) error
+	// ListTrash возвращает до limit задач в корзине, начиная с последней;
+	// cursor — позиция после последней задачи предыдущей страницы, nil — с начала
+	ListTrash(limit int, cursor *TaskCursor) ([]Task, error)
+	// GetTrashedTask возвращает задачу из корзины
+	GetTrashedTask(id string) (*Task, error)
+	// RestoreTask возвращает задачу из корзины
+	RestoreTask(id string) error
+	// PurgeTask окончательно удаляет задачу вместе с её зависимостями; история выполнений сохраняется
+	PurgeTask(id string) error
+	// PurgeTrash окончательно удаляет задачи, попавшие в корзину раньше before,
+	// а при нулевом before — всю корзину. Возвращает число удалённых задач.
+	PurgeTrash(before time.Time) (int, error)
+
+	// AddExDate добавляет дату-исключение задачи
+	AddExDate(id, date string) error
+	// DeleteExDate удаляет дату-исключение задачи
+	DeleteExDate(id, date string) error
+	// SkipOccurrence одной транзакцией добавляет дату-исключение date и, если next не nil,
+	// переносит задачу на следующее повторение next
+	SkipOccurrence(id, date string, next *Occurrence) error
+
+	// ListTags возвращает метки с числом активных задач
+	ListTags() ([]Tag, error)
+	CreateTag(name string) (int64, error)
+	RenameTag(id, name string) error
+	// DeleteTag удаляет метку и снимает её с задач
+	DeleteTag(id string) error
+
+	// ListProjects возвращает проекты с числом активных задач, архивные — при archived
+	ListProjects(archived bool) ([]Project, error)
+	GetProject(id int64) (*Project, error)
+	CreateProject(p *Project) (int64, error)
+	UpdateProject(p *Project) error
+	// DeleteProject удаляет проект, оставляя его задачи без проекта
+	DeleteProject(id string) error
+
+	// AddChecklistItem добавляет пункт в чек-лист задачи на позицию position
+	AddChecklistItem(id string, item ChecklistItem, position int) (int64, error)
+	UpdateChecklistItem(id string, item ChecklistItem) error
+	DeleteChecklistItem(id, itemID string) error
+	// ResetChecklist снимает отметки со всех пунктов чек-листа задачи
+	ResetChecklist(id string) error
+	// CompleteChecklist отмечает выполненными все пункты чек-листа задачи
+	CompleteChecklist(id string) error
+
+	// AddDependency отмечает, что задача id заблокирована задачей blocker;
+	// зависимость, замыкающая цикл, не добавляется
+	AddDependency(id, blocker string) error
+	DeleteDependency(id, blocker string) error
+	// UnblockDependents снимает блокировку, которую задача id накладывала на другие задачи.
+	// Зависимости сохраняются снятыми, пока зависимая задача не вызовет RearmDependencies.
+	UnblockDependents(id string) error
+	// RearmDependencies снова блокирует задачу id всеми её зависимостями
+	RearmDependencies(id string) error
+
+	// AddCompletion записывает выполнение задачи id
+	AddCompletion(id string, c Completion) error
+	// CompleteOccurrence выполняет задачу id одной транзакцией: записывает выполнение c,
+	// при cascade отмечает пункты чек-листа и снимает блокировку с зависимых задач.
+	// Если next не nil, задача переносится на следующее повторение: чек-лист сбрасывается,
+	// а её зависимости снова её блокируют. Иначе задача попадает в корзину как выполненная.
+	CompleteOccurrence(id string, c Completion, next *Occurrence, cascade bool) error
+	// ListCompletions возвращает историю выполнений задачи, начиная с последнего
+	ListCompletions(id string) ([]Completion, error)
+}
+
+// Occurrence — следующее повторение задачи после выполнения
+type Occurrence struct {
+	Date string
+	Time string
+	// Repeat — правило повторения для оставшейся серии (с уменьшенным count)
+	Repeat string
+	// Anchor — дата повторения по графику до переноса roll, пустая — совпадает с Date
+	Anchor string
+}
+
+// TaskFilter — условия выборки задач для ListTasks
+type TaskFilter struct {
+	// Query — поисковый запрос (см. ParseQuery), nil — все задачи. Задачи, найденные
+	// по тексту, упорядочиваются по релевантности и получают фрагмент текста Snippet.
+	Query *Query
+	// ProjectID — проект задач, 0 — задачи вне проектов; nil — все задачи,
+	// кроме задач архивных проектов
+	ProjectID *int64
+	// Blocked — только заблокированные (true) или только незаблокированные (false) задачи
+	Blocked *bool
+	// Priorities — допустимые приоритеты, пустой — любые
+	Priorities []int
+	// Cursor — позиция, после которой начинается выборка; nil — с начала списка
+	Cursor *TaskCursor
+	// Limit — наибольшее число задач
+	Limit int
+}
+
+// TaskCursor — позиция в списке задач: последняя выданная задача (Date, Priority, ID)
+// или, для задач в порядке релевантности, число уже выданных задач Offset.
+// В корзине позиция — время попадания в корзину TrashedAt и ID последней задачи.
+type TaskCursor struct {
+	Date      string `json:"d,omitempty"`
+	Priority  int    `json:"p,omitempty"`
+	ID        int64  `json:"i,omitempty"`
+	Offset    int    `json:"o,omitempty"`
+	TrashedAt string `json:"t,omitempty"`
+}
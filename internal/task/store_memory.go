@@ -0,0 +1,897 @@
+package task
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go_final_project/internal/logger"
+)
+
+// memoryStore хранит задачи в памяти процесса: данные теряются при остановке
+// сервера. Подходит для тестов и демонстрации.
+type memoryStore struct {
+	mu sync.Mutex
+
+	// seq — последние выданные идентификаторы по видам записей
+	seq map[string]int64
+
+	tasks       map[int64]*Task
+	exdates     map[int64]map[string]bool
+	tags        map[int64]string
+	taskTags    map[int64]map[int64]bool
+	projects    map[int64]*Project
+	checklist   map[int64][]memoryItem
+	deps        map[memoryDep]bool
+	completions []memoryCompletion
+}
+
+type memoryItem struct {
+	ChecklistItem
+	position int
+}
+
+// memoryDep — задача task ждёт выполнения задачи blocker. Значение в deps
+// показывает, что зависимость снята выполнением blocker.
+type memoryDep struct {
+	task, blocker int64
+}
+
+type memoryCompletion struct {
+	Completion
+	task int64
+}
+
+// NewMemoryStore возвращает пустое хранилище задач в памяти
+func NewMemoryStore() TaskStore {
+	return &memoryStore{
+		seq:       map[string]int64{},
+		tasks:     map[int64]*Task{},
+		exdates:   map[int64]map[string]bool{},
+		tags:      map[int64]string{},
+		taskTags:  map[int64]map[int64]bool{},
+		projects:  map[int64]*Project{},
+		checklist: map[int64][]memoryItem{},
+		deps:      map[memoryDep]bool{},
+	}
+}
+
+func (s *memoryStore) nextID(kind string) int64 {
+	s.seq[kind]++
+	return s.seq[kind]
+}
+
+// memoryID разбирает идентификатор; некорректный идентификатор не совпадает
+// ни с одной записью, как и в SQL-хранилище
+func memoryID(id string) int64 {
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+func (s *memoryStore) activeTask(id int64) *Task {
+	if t := s.tasks[id]; t != nil && t.DeletedAt == "" && t.CompletedAt == "" {
+		return t
+	}
+	return nil
+}
+
+// row возвращает копию задачи без вычисляемых полей
+func (s *memoryStore) row(t *Task) Task {
+	var projectID *int64
+	if t.ProjectID != nil {
+		id := *t.ProjectID
+		projectID = &id
+	}
+	return Task{
+		ID:          t.ID,
+		Date:        t.Date,
+		Title:       t.Title,
+		Comment:     t.Comment,
+		Repeat:      t.Repeat,
+		AnchorDate:  t.AnchorDate,
+		Time:        t.Time,
+		Timezone:    t.Timezone,
+		Priority:    t.Priority,
+		ProjectID:   projectID,
+		DeletedAt:   t.DeletedAt,
+		CompletedAt: t.CompletedAt,
+	}
+}
+
+func (s *memoryStore) AddTask(t *Task) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID("task")
+	row := s.row(t)
+	row.ID = strconv.FormatInt(id, 10)
+	row.DeletedAt, row.CompletedAt = "", ""
+	s.tasks[id] = &row
+	s.setTaskTags(id, t.Tags)
+	s.setChecklist(id, t.Checklist)
+	return id, nil
+}
+
+func (s *memoryStore) GetTask(id string) (*Task, error) {
+	numericID, err := parseTaskID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.activeTask(numericID)
+	if t == nil {
+		logger.LogMessage("[ERROR] Задача не найдена")
+		return nil, errors.New("Задача не найдена")
+	}
+	task := s.row(t)
+	for date := range s.exdates[numericID] {
+		task.ExDates = append(task.ExDates, date)
+	}
+	sort.Strings(task.ExDates)
+	for _, item := range s.checklist[numericID] {
+		task.Checklist = append(task.Checklist, item.ChecklistItem)
+	}
+	var blockedBy, blocking []int64
+	for dep, resolved := range s.deps {
+		if resolved {
+			continue
+		}
+		if dep.task == numericID && s.activeTask(dep.blocker) != nil {
+			blockedBy = append(blockedBy, dep.blocker)
+		}
+		if dep.blocker == numericID && s.activeTask(dep.task) != nil {
+			blocking = append(blocking, dep.task)
+		}
+	}
+	task.BlockedBy = formatIDs(blockedBy)
+	task.Blocking = formatIDs(blocking)
+	task.Blocked = len(task.BlockedBy) > 0
+	task.Tags = s.taskTagNames(numericID)
+	return &task, nil
+}
+
+// formatIDs сортирует идентификаторы и переводит их в строки
+func formatIDs(ids []int64) []string {
+	slices.Sort(ids)
+	var result []string
+	for _, id := range ids {
+		result = append(result, strconv.FormatInt(id, 10))
+	}
+	return result
+}
+
+func (s *memoryStore) UpdateTask(task *Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := memoryID(task.ID)
+	t := s.tasks[id]
+	if t == nil {
+		return nil
+	}
+	row := s.row(task)
+	row.ID, row.DeletedAt, row.CompletedAt = t.ID, t.DeletedAt, t.CompletedAt
+	*t = row
+	if task.Tags != nil {
+		s.setTaskTags(id, task.Tags)
+	}
+	if task.Checklist != nil {
+		s.setChecklist(id, task.Checklist)
+	}
+	return nil
+}
+
+func (s *memoryStore) ListTasks(f TaskFilter) ([]Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := []Task{}
+	ranks := map[string]int{}
+	terms := f.Query.rankTerms()
+	for id, t := range s.tasks {
+		if s.activeTask(id) == nil || !s.matchTask(id, t, f) {
+			continue
+		}
+		if c := f.Cursor; c != nil && c.ID > 0 && !afterCursor(t, id, c) {
+			continue
+		}
+		task := s.row(t)
+		if len(terms) > 0 {
+			if rank := searchRank(&task, terms); rank > 0 {
+				ranks[task.ID] = rank
+				task.Snippet = searchSnippet(&task, terms)
+			}
+		}
+		task.Tags = s.taskTagNames(id)
+		task.Blocked = s.blocked(id)
+		tasks = append(tasks, task)
+	}
+
+	// Задачи сортируются по релевантности при поиске, затем по дате,
+	// а в пределах дня срочные идут первыми
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if ranks[a.ID] != ranks[b.ID] {
+			return ranks[a.ID] > ranks[b.ID]
+		}
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		return memoryID(a.ID) < memoryID(b.ID)
+	})
+	if f.Cursor != nil {
+		tasks = tasks[min(f.Cursor.Offset, len(tasks)):]
+	}
+	if len(tasks) > f.Limit {
+		tasks = tasks[:f.Limit]
+	}
+	return tasks, nil
+}
+
+func (s *memoryStore) CountTasks(f TaskFilter) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for id, t := range s.tasks {
+		if s.activeTask(id) != nil && s.matchTask(id, t, f) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// afterCursor проверяет, что задача идёт в списке после позиции c
+func afterCursor(t *Task, id int64, c *TaskCursor) bool {
+	if t.Date != c.Date {
+		return t.Date > c.Date
+	}
+	if t.Priority != c.Priority {
+		return t.Priority > c.Priority
+	}
+	return id > c.ID
+}
+
+func (s *memoryStore) matchTask(id int64, t *Task, f TaskFilter) bool {
+	if !f.Query.empty() && !s.matchQuery(id, t, f.Query.root) {
+		return false
+	}
+	switch {
+	case f.ProjectID == nil:
+		if t.ProjectID != nil {
+			if p := s.projects[*t.ProjectID]; p != nil && p.Archived {
+				return false
+			}
+		}
+	case *f.ProjectID == 0:
+		if t.ProjectID != nil {
+			return false
+		}
+	default:
+		if t.ProjectID == nil || *t.ProjectID != *f.ProjectID {
+			return false
+		}
+	}
+	if f.Blocked != nil && s.blocked(id) != *f.Blocked {
+		return false
+	}
+	if len(f.Priorities) > 0 && !slices.Contains(f.Priorities, t.Priority) {
+		return false
+	}
+	return true
+}
+
+// matchQuery проверяет задачу на соответствие поисковому запросу
+func (s *memoryStore) matchQuery(id int64, t *Task, n *queryNode) bool {
+	switch n.kind {
+	case queryAnd:
+		for _, child := range n.children {
+			if !s.matchQuery(id, t, child) {
+				return false
+			}
+		}
+		return true
+	case queryOr:
+		for _, child := range n.children {
+			if s.matchQuery(id, t, child) {
+				return true
+			}
+		}
+		return false
+	case queryNot:
+		return !s.matchQuery(id, t, n.children[0])
+	case queryText:
+		return textMatches(t, n.term)
+	case queryDate:
+		return (n.from == "" || t.Date >= n.from) && (n.to == "" || t.Date <= n.to)
+	case queryTag:
+		return slices.ContainsFunc(s.taskTagNames(id), func(name string) bool {
+			return slices.Contains(n.tags, name)
+		})
+	case queryRepeat:
+		return (t.Repeat != "") == n.repeat
+	}
+	return false
+}
+
+// blocked проверяет, что задача id ждёт задачу вне корзины
+func (s *memoryStore) blocked(id int64) bool {
+	for dep, resolved := range s.deps {
+		if dep.task == id && !resolved && s.activeTask(dep.blocker) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *memoryStore) RescheduleTask(id, date, clock string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t := s.tasks[memoryID(id)]; t != nil {
+		t.Date, t.Time, t.AnchorDate = date, clock, ""
+	}
+	return nil
+}
+
+// moveToOccurrence переносит задачу на следующее повторение next
+func moveToOccurrence(t *Task, next *Occurrence) {
+	t.Date, t.Time, t.Repeat, t.AnchorDate = next.Date, next.Time, next.Repeat, next.Anchor
+}
+
+func (s *memoryStore) SetTaskRepeat(id, repeat string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t := s.tasks[memoryID(id)]; t != nil {
+		t.Repeat = repeat
+	}
+	return nil
+}
+
+func (s *memoryStore) TrashTask(id string, completed bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t := s.tasks[memoryID(id)]; t != nil {
+		now := time.Now().UTC().Format(time.RFC3339)
+		if completed {
+			t.CompletedAt = now
+		} else {
+			t.DeletedAt = now
+		}
+	}
+	return nil
+}
+
+// trashedAt возвращает время, когда задача попала в корзину
+func trashedAt(t *Task) string {
+	return max(t.DeletedAt, t.CompletedAt)
+}
+
+func (s *memoryStore) ListTrash(limit int, cursor *TaskCursor) ([]Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := []Task{}
+	for id, t := range s.tasks {
+		at := trashedAt(t)
+		if at == "" || cursor != nil && (at > cursor.TrashedAt || at == cursor.TrashedAt && id >= cursor.ID) {
+			continue
+		}
+		tasks = append(tasks, s.row(t))
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		a, b := trashedAt(&tasks[i]), trashedAt(&tasks[j])
+		if a != b {
+			return a > b
+		}
+		return memoryID(tasks[i].ID) > memoryID(tasks[j].ID)
+	})
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks, nil
+}
+
+func (s *memoryStore) GetTrashedTask(id string) (*Task, error) {
+	numericID, err := parseTaskID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := s.tasks[numericID]
+	if t == nil || trashedAt(t) == "" {
+		logger.LogMessage("[ERROR] Задача не найдена в корзине: " + id)
+		return nil, errors.New("задача не найдена в корзине")
+	}
+	task := s.row(t)
+	return &task, nil
+}
+
+func (s *memoryStore) RestoreTask(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t := s.tasks[memoryID(id)]; t != nil {
+		t.DeletedAt, t.CompletedAt = "", ""
+	}
+	return nil
+}
+
+func (s *memoryStore) PurgeTask(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purgeTask(memoryID(id))
+	return nil
+}
+
+func (s *memoryStore) purgeTask(id int64) {
+	delete(s.tasks, id)
+	delete(s.exdates, id)
+	delete(s.taskTags, id)
+	delete(s.checklist, id)
+	s.deleteDeps(id)
+}
+
+func (s *memoryStore) PurgeTrash(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	at := before.UTC().Format(time.RFC3339)
+	purged := 0
+	for id, t := range s.tasks {
+		if trashedAt(t) == "" {
+			continue
+		}
+		if !before.IsZero() && !(t.DeletedAt != "" && t.DeletedAt < at || t.CompletedAt != "" && t.CompletedAt < at) {
+			continue
+		}
+		s.purgeTask(id)
+		purged++
+	}
+	return purged, nil
+}
+
+func (s *memoryStore) AddExDate(id, date string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addExDate(memoryID(id), date)
+	return nil
+}
+
+func (s *memoryStore) addExDate(id int64, date string) {
+	if s.exdates[id] == nil {
+		s.exdates[id] = map[string]bool{}
+	}
+	s.exdates[id][date] = true
+}
+
+func (s *memoryStore) SkipOccurrence(id, date string, next *Occurrence) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	numericID := memoryID(id)
+	s.addExDate(numericID, date)
+	if t := s.tasks[numericID]; t != nil && next != nil {
+		moveToOccurrence(t, next)
+	}
+	return nil
+}
+
+func (s *memoryStore) DeleteExDate(id, date string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.exdates[memoryID(id)], date)
+	return nil
+}
+
+func (s *memoryStore) ListTags() ([]Tag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tags := []Tag{}
+	for id, name := range s.tags {
+		tag := Tag{ID: strconv.FormatInt(id, 10), Name: name}
+		for taskID, ids := range s.taskTags {
+			if ids[id] && s.activeTask(taskID) != nil {
+				tag.Tasks++
+			}
+		}
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+// tagID возвращает идентификатор метки name, 0 — если метки нет
+func (s *memoryStore) tagID(name string) int64 {
+	for id, tag := range s.tags {
+		if tag == name {
+			return id
+		}
+	}
+	return 0
+}
+
+func (s *memoryStore) CreateTag(name string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tagID(name) != 0 {
+		logger.LogMessage("[ERROR] Ошибка создания метки " + name + ": метка уже существует")
+		return 0, errors.New("метка уже существует: " + name)
+	}
+	id := s.nextID("tag")
+	s.tags[id] = name
+	return id, nil
+}
+
+func (s *memoryStore) RenameTag(id, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	numericID := memoryID(id)
+	if existing := s.tagID(name); existing != 0 && existing != numericID {
+		logger.LogMessage("[ERROR] Ошибка переименования метки с ID " + id + ": метка уже существует")
+		return errors.New("метка уже существует: " + name)
+	}
+	if _, ok := s.tags[numericID]; !ok {
+		logger.LogMessage("[ERROR] Метка не найдена")
+		return errors.New("метка не найдена")
+	}
+	s.tags[numericID] = name
+	return nil
+}
+
+func (s *memoryStore) DeleteTag(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	numericID := memoryID(id)
+	if _, ok := s.tags[numericID]; !ok {
+		logger.LogMessage("[ERROR] Метка не найдена")
+		return errors.New("метка не найдена")
+	}
+	delete(s.tags, numericID)
+	for _, ids := range s.taskTags {
+		delete(ids, numericID)
+	}
+	return nil
+}
+
+// setTaskTags заменяет метки задачи; недостающие метки создаются
+func (s *memoryStore) setTaskTags(id int64, tags []string) {
+	s.taskTags[id] = map[int64]bool{}
+	for _, name := range tags {
+		tagID := s.tagID(name)
+		if tagID == 0 {
+			tagID = s.nextID("tag")
+			s.tags[tagID] = name
+		}
+		s.taskTags[id][tagID] = true
+	}
+}
+
+func (s *memoryStore) taskTagNames(id int64) []string {
+	var names []string
+	for tagID := range s.taskTags[id] {
+		names = append(names, s.tags[tagID])
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *memoryStore) ListProjects(archived bool) ([]Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projects := []Project{}
+	for id, p := range s.projects {
+		if p.Archived && !archived {
+			continue
+		}
+		project := *p
+		project.Tasks = 0
+		for taskID, t := range s.tasks {
+			if t.ProjectID != nil && *t.ProjectID == id && s.activeTask(taskID) != nil {
+				project.Tasks++
+			}
+		}
+		projects = append(projects, project)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Name != projects[j].Name {
+			return projects[i].Name < projects[j].Name
+		}
+		return memoryID(projects[i].ID) < memoryID(projects[j].ID)
+	})
+	return projects, nil
+}
+
+func (s *memoryStore) GetProject(id int64) (*Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.projects[id]
+	if p == nil {
+		logger.LogMessage("[ERROR] Проект не найден: " + strconv.FormatInt(id, 10))
+		return nil, errors.New("проект не найден")
+	}
+	project := *p
+	return &project, nil
+}
+
+func (s *memoryStore) CreateProject(p *Project) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID("project")
+	s.projects[id] = &Project{ID: strconv.FormatInt(id, 10), Name: p.Name, Color: p.Color, Archived: p.Archived}
+	return id, nil
+}
+
+func (s *memoryStore) UpdateProject(p *Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project := s.projects[memoryID(p.ID)]
+	if project == nil {
+		logger.LogMessage("[ERROR] Проект не найден")
+		return errors.New("проект не найден")
+	}
+	project.Name, project.Color, project.Archived = p.Name, p.Color, p.Archived
+	return nil
+}
+
+func (s *memoryStore) DeleteProject(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	numericID := memoryID(id)
+	if s.projects[numericID] == nil {
+		logger.LogMessage("[ERROR] Проект не найден")
+		return errors.New("проект не найден")
+	}
+	delete(s.projects, numericID)
+	for _, t := range s.tasks {
+		if t.ProjectID != nil && *t.ProjectID == numericID {
+			t.ProjectID = nil
+		}
+	}
+	return nil
+}
+
+// setChecklist заменяет чек-лист задачи пунктами items
+func (s *memoryStore) setChecklist(id int64, items []ChecklistItem) {
+	s.checklist[id] = nil
+	for position, item := range items {
+		s.addChecklistItem(id, item, position)
+	}
+}
+
+func (s *memoryStore) addChecklistItem(id int64, item ChecklistItem, position int) int64 {
+	itemID := s.nextID("checklist")
+	item.ID = strconv.FormatInt(itemID, 10)
+	items := append(s.checklist[id], memoryItem{ChecklistItem: item, position: position})
+	sort.SliceStable(items, func(i, j int) bool { return items[i].position < items[j].position })
+	s.checklist[id] = items
+	return itemID
+}
+
+func (s *memoryStore) AddChecklistItem(id string, item ChecklistItem, position int) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addChecklistItem(memoryID(id), item, position), nil
+}
+
+// checklistItem возвращает пункт itemID чек-листа задачи id
+func (s *memoryStore) checklistItem(id, itemID string) (int, error) {
+	i := slices.IndexFunc(s.checklist[memoryID(id)], func(item memoryItem) bool {
+		return item.ID == itemID
+	})
+	if i < 0 {
+		logger.LogMessage("[ERROR] Пункт чек-листа не найден")
+		return 0, errors.New("пункт чек-листа не найден")
+	}
+	return i, nil
+}
+
+func (s *memoryStore) UpdateChecklistItem(id string, item ChecklistItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.checklistItem(id, item.ID)
+	if err != nil {
+		return err
+	}
+	stored := &s.checklist[memoryID(id)][i]
+	stored.Title, stored.Done = item.Title, item.Done
+	return nil
+}
+
+func (s *memoryStore) DeleteChecklistItem(id, itemID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.checklistItem(id, itemID)
+	if err != nil {
+		return err
+	}
+	numericID := memoryID(id)
+	s.checklist[numericID] = slices.Delete(s.checklist[numericID], i, i+1)
+	return nil
+}
+
+func (s *memoryStore) ResetChecklist(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.checklist[memoryID(id)] {
+		s.checklist[memoryID(id)][i].Done = false
+	}
+	return nil
+}
+
+func (s *memoryStore) CompleteChecklist(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.checklist[memoryID(id)] {
+		s.checklist[memoryID(id)][i].Done = true
+	}
+	return nil
+}
+
+func (s *memoryStore) AddDependency(id, blocker string) error {
+	if id == blocker {
+		logger.LogMessage("[ERROR] Задача не может блокировать саму себя")
+		return errors.New("задача не может блокировать саму себя")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Цикл возникает, если blocker уже прямо или через другие задачи ждёт id
+	taskID, blockerID := memoryID(id), memoryID(blocker)
+	chain := []int64{blockerID}
+	seen := map[int64]bool{blockerID: true}
+	for len(chain) > 0 {
+		current := chain[0]
+		chain = chain[1:]
+		if current == taskID {
+			logger.LogMessage("[ERROR] Зависимость образует цикл: " + id + " <- " + blocker)
+			return errors.New("зависимость образует цикл")
+		}
+		for dep := range s.deps {
+			if dep.task == current && !seen[dep.blocker] {
+				seen[dep.blocker] = true
+				chain = append(chain, dep.blocker)
+			}
+		}
+	}
+	s.deps[memoryDep{task: taskID, blocker: blockerID}] = false
+	return nil
+}
+
+func (s *memoryStore) DeleteDependency(id, blocker string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.deps, memoryDep{task: memoryID(id), blocker: memoryID(blocker)})
+	return nil
+}
+
+func (s *memoryStore) UnblockDependents(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	numericID := memoryID(id)
+	for dep := range s.deps {
+		if dep.blocker == numericID {
+			s.deps[dep] = true
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) RearmDependencies(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	numericID := memoryID(id)
+	for dep := range s.deps {
+		if dep.task == numericID {
+			s.deps[dep] = false
+		}
+	}
+	return nil
+}
+
+// deleteDeps удаляет все зависимости, в которых участвует задача id
+func (s *memoryStore) deleteDeps(id int64) {
+	for dep := range s.deps {
+		if dep.task == id || dep.blocker == id {
+			delete(s.deps, dep)
+		}
+	}
+}
+
+func (s *memoryStore) AddCompletion(id string, c Completion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addCompletion(memoryID(id), c)
+	return nil
+}
+
+func (s *memoryStore) addCompletion(id int64, c Completion) {
+	c.ID = strconv.FormatInt(s.nextID("completion"), 10)
+	s.completions = append(s.completions, memoryCompletion{Completion: c, task: id})
+}
+
+func (s *memoryStore) CompleteOccurrence(id string, c Completion, next *Occurrence, cascade bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	numericID := memoryID(id)
+	t := s.tasks[numericID]
+	if t == nil {
+		return nil
+	}
+	s.addCompletion(numericID, c)
+	for i := range s.checklist[numericID] {
+		if next != nil {
+			s.checklist[numericID][i].Done = false
+		} else if cascade {
+			s.checklist[numericID][i].Done = true
+		}
+	}
+	for dep := range s.deps {
+		if dep.blocker == numericID {
+			s.deps[dep] = true
+		}
+		if next != nil && dep.task == numericID {
+			s.deps[dep] = false
+		}
+	}
+	if next == nil {
+		t.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+		return nil
+	}
+	moveToOccurrence(t, next)
+	return nil
+}
+
+func (s *memoryStore) ListCompletions(id string) ([]Completion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := []Completion{}
+	numericID := memoryID(id)
+	for i := len(s.completions) - 1; i >= 0; i-- {
+		if s.completions[i].task == numericID {
+			history = append(history, s.completions[i].Completion)
+		}
+	}
+	return history, nil
+}
@@ -0,0 +1,1005 @@
+package task
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"go_final_project/internal/logger"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// taskColumns — столбцы таблицы scheduler, которые читаются в Task
+const taskColumns = "id, date, title, comment, repeat, anchor_date, time, timezone, priority, project_id, deleted_at, completed_at"
+
+// activeTask — условие для задач, которые не находятся в корзине
+const activeTask = "deleted_at = '' AND completed_at = ''"
+
+// activeTaskIDs — подзапрос идентификаторов задач вне корзины. Зависимости задачи
+// в корзине сохраняются до её окончательного удаления, но блокировки не создают.
+const activeTaskIDs = "SELECT id FROM scheduler WHERE " + activeTask
+
+// blockingDep — условие для зависимостей, которые блокируют задачу сейчас:
+// не снятые выполнением и с блокирующей задачей вне корзины
+const blockingDep = "resolved = 0 AND blocked_by IN (" + activeTaskIDs + ")"
+
+// postgresSearchVector — текст задачи для полнотекстового поиска в PostgreSQL;
+// совпадает с выражением индекса idx_scheduler_search
+const postgresSearchVector = "to_tsvector('simple', title || ' ' || coalesce(comment, ''))"
+
+// sqlStore хранит задачи в SQLite или PostgreSQL. Запросы пишутся с
+// плейсхолдерами ? и приводятся к синтаксису драйвера через Rebind.
+type sqlStore struct {
+	db *sqlx.DB
+	// tx — открытая транзакция, в которой выполняются запросы; nil — вне транзакции
+	tx       *sqlx.Tx
+	postgres bool
+}
+
+// sqlQueryer — методы выполнения запросов, общие для *sqlx.DB и *sqlx.Tx
+type sqlQueryer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Get(dest interface{}, query string, args ...interface{}) error
+	Select(dest interface{}, query string, args ...interface{}) error
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// NewSQLStore возвращает хранилище задач в базе данных db (SQLite или PostgreSQL)
+func NewSQLStore(db *sqlx.DB) TaskStore {
+	return &sqlStore{db: db, postgres: db.DriverName() == "postgres"}
+}
+
+func (s *sqlStore) conn() sqlQueryer {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.db
+}
+
+// inTx выполняет fn в одной транзакции: если fn возвращает ошибку, все её изменения
+// откатываются. Внутри уже открытой транзакции fn выполняется в ней же.
+func (s *sqlStore) inTx(fn func(tx *sqlStore) error) error {
+	if s.tx != nil {
+		return fn(s)
+	}
+	tx, err := s.db.Beginx()
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка начала транзакции: " + err.Error())
+		log.Printf("Ошибка начала транзакции: %v", err)
+		return errors.New("ошибка начала транзакции")
+	}
+	if err := fn(&sqlStore{db: s.db, tx: tx, postgres: s.postgres}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		logger.LogMessage("[ERROR] Ошибка сохранения транзакции: " + err.Error())
+		log.Printf("Ошибка сохранения транзакции: %v", err)
+		return errors.New("ошибка сохранения транзакции")
+	}
+	return nil
+}
+
+func (s *sqlStore) exec(query string, args ...interface{}) (sql.Result, error) {
+	return s.conn().Exec(s.db.Rebind(query), args...)
+}
+
+func (s *sqlStore) get(dest interface{}, query string, args ...interface{}) error {
+	return s.conn().Get(dest, s.db.Rebind(query), args...)
+}
+
+func (s *sqlStore) selectAll(dest interface{}, query string, args ...interface{}) error {
+	return s.conn().Select(dest, s.db.Rebind(query), args...)
+}
+
+// insert выполняет INSERT и возвращает идентификатор новой строки;
+// драйвер PostgreSQL не поддерживает LastInsertId, поэтому используется RETURNING
+func (s *sqlStore) insert(query string, args ...interface{}) (int64, error) {
+	var id int64
+	err := s.conn().QueryRow(s.db.Rebind(query+" RETURNING id"), args...).Scan(&id)
+	return id, err
+}
+
+// boolInt переводит признак в 0 или 1: такие столбцы имеют тип INTEGER в обеих базах
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// placeholders возвращает список из n плейсхолдеров через запятую
+func placeholders(n int) string {
+	return "?" + strings.Repeat(", ?", n-1)
+}
+
+func (s *sqlStore) AddTask(t *Task) (id int64, err error) {
+	err = s.inTx(func(tx *sqlStore) error {
+		id, err = tx.addTask(t)
+		return err
+	})
+	return id, err
+}
+
+func (s *sqlStore) addTask(t *Task) (int64, error) {
+	query := `INSERT INTO scheduler (date, title, comment, repeat, anchor_date, time, timezone, priority, project_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	id, err := s.insert(query, t.Date, t.Title, t.Comment, t.Repeat, t.AnchorDate, t.Time, t.Timezone, t.Priority, t.ProjectID)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка SQL: " + err.Error())
+		log.Println("Ошибка SQL:", err)
+		return 0, errors.New("ошибка сохранения в БД")
+	}
+	if err := s.setTaskTags(strconv.FormatInt(id, 10), t.Tags); err != nil {
+		return 0, err
+	}
+	if err := s.setChecklist(strconv.FormatInt(id, 10), t.Checklist); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *sqlStore) GetTask(id string) (*Task, error) {
+	numericID, err := parseTaskID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var task Task
+	err = s.get(&task, "SELECT "+taskColumns+" FROM scheduler WHERE id = ? AND "+activeTask, numericID)
+	if err != nil {
+		logger.LogMessage("[ERROR] Задача не найдена")
+		log.Printf("Задача с ID %d не найдена: %v", numericID, err)
+		return nil, errors.New("Задача не найдена")
+	}
+
+	task.ID = strconv.FormatInt(numericID, 10)
+	task.ExDates, err = s.getTaskExDates(task.ID)
+	if err != nil {
+		return nil, err
+	}
+	task.Checklist, err = s.getChecklist(task.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadDependencies(&task); err != nil {
+		return nil, err
+	}
+	tasks := []Task{task}
+	if err := s.loadTaskTags(tasks); err != nil {
+		return nil, err
+	}
+	return &tasks[0], nil
+}
+
+func (s *sqlStore) UpdateTask(task *Task) error {
+	return s.inTx(func(tx *sqlStore) error {
+		return tx.updateTask(task)
+	})
+}
+
+func (s *sqlStore) updateTask(task *Task) error {
+	query := `UPDATE scheduler SET date=?, title=?, comment=?, repeat=?, anchor_date=?, time=?, timezone=?, priority=?, project_id=? WHERE id=?`
+	_, err := s.exec(query, task.Date, task.Title, task.Comment, task.Repeat, task.AnchorDate, task.Time, task.Timezone, task.Priority, task.ProjectID, task.ID)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка обновления задачи: " + err.Error())
+		log.Printf("Ошибка обновления задачи с ID %s: %v", task.ID, err)
+		return errors.New("ошибка обновления задачи")
+	}
+	if task.Tags != nil {
+		if err := s.setTaskTags(task.ID, task.Tags); err != nil {
+			return err
+		}
+	}
+	if task.Checklist != nil {
+		return s.setChecklist(task.ID, task.Checklist)
+	}
+	return nil
+}
+
+func (s *sqlStore) ListTasks(f TaskFilter) ([]Task, error) {
+	conditions, args := s.taskConditions(f)
+
+	columns, from, order := taskColumns, "scheduler", "date, priority, id"
+	var fromArgs, orderArgs []interface{}
+	// Задачи, найденные по тексту, идут первыми в порядке релевантности
+	terms := f.Query.rankTerms()
+	switch {
+	case len(terms) == 0:
+	case s.postgres:
+		ranked := make([]string, len(terms))
+		for i, term := range terms {
+			ranked[i] = tsTerm(term)
+		}
+		order = "ts_rank(" + postgresSearchVector + ", to_tsquery('simple', ?)) DESC, " + order
+		orderArgs = append(orderArgs, strings.Join(ranked, " | "))
+	default:
+		ranked := make([]string, len(terms))
+		for i, term := range terms {
+			ranked[i] = ftsTerm(term)
+		}
+		from = fmt.Sprintf(`scheduler LEFT JOIN (SELECT rowid, bm25(scheduler_fts, %d, 1) AS score,
+			snippet(scheduler_fts, -1, char(%d), char(%d), '%s', %d) AS snippet
+			FROM scheduler_fts WHERE scheduler_fts MATCH ?) fts ON fts.rowid = scheduler.id`,
+			titleWeight, ftsMarkOpen, ftsMarkClose, snippetEllipsis, snippetTokens)
+		fromArgs = append(fromArgs, strings.Join(ranked, " OR "))
+		columns += ", coalesce(fts.snippet, '') AS snippet"
+		order = "fts.score IS NULL, fts.score, " + order
+	}
+
+	offset := 0
+	if c := f.Cursor; c != nil {
+		if c.ID > 0 {
+			conditions = append(conditions, "(date, priority, id) > (?, ?, ?)")
+			args = append(args, c.Date, c.Priority, c.ID)
+		}
+		offset = c.Offset
+	}
+
+	// Задачи сортируются по релевантности при поиске, затем по дате,
+	// а в пределах дня срочные идут первыми
+	query := "SELECT " + columns + " FROM " + from + " WHERE " + strings.Join(conditions, " AND ") +
+		" ORDER BY " + order + " LIMIT ? OFFSET ?"
+	args = append(append(append(fromArgs, args...), orderArgs...), f.Limit, offset)
+
+	tasks := []Task{}
+	if err := s.selectAll(&tasks, query, args...); err != nil {
+		logger.LogMessage("[ERROR] Ошибка при извлечении данных: " + err.Error())
+		log.Printf("Ошибка при извлечении данных: %v", err)
+		return nil, errors.New("ошибка при извлечении данных")
+	}
+	if err := s.loadTaskTags(tasks); err != nil {
+		return nil, err
+	}
+	if err := s.markBlocked(tasks); err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		switch {
+		case tasks[i].Snippet != "":
+			tasks[i].Snippet = ftsSnippet(tasks[i].Snippet)
+		case s.postgres && len(terms) > 0 && searchRank(&tasks[i], terms) > 0:
+			tasks[i].Snippet = searchSnippet(&tasks[i], terms)
+		}
+	}
+	return tasks, nil
+}
+
+func (s *sqlStore) CountTasks(f TaskFilter) (int, error) {
+	conditions, args := s.taskConditions(f)
+	var count int
+	if err := s.get(&count, "SELECT count(*) FROM scheduler WHERE "+strings.Join(conditions, " AND "), args...); err != nil {
+		logger.LogMessage("[ERROR] Ошибка подсчёта задач: " + err.Error())
+		log.Printf("Ошибка подсчёта задач: %v", err)
+		return 0, errors.New("ошибка подсчёта задач")
+	}
+	return count, nil
+}
+
+// taskConditions переводит фильтр, кроме позиции Cursor, в условия WHERE
+func (s *sqlStore) taskConditions(f TaskFilter) ([]string, []interface{}) {
+	conditions := []string{activeTask}
+	var args []interface{}
+
+	if !f.Query.empty() {
+		condition, queryArgs := s.queryCondition(f.Query.root)
+		conditions = append(conditions, condition)
+		args = append(args, queryArgs...)
+	}
+	switch {
+	case f.ProjectID == nil:
+		conditions = append(conditions, "(project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived = 1))")
+	case *f.ProjectID == 0:
+		conditions = append(conditions, "project_id IS NULL")
+	default:
+		conditions = append(conditions, "project_id = ?")
+		args = append(args, *f.ProjectID)
+	}
+	if f.Blocked != nil {
+		in := "IN"
+		if !*f.Blocked {
+			in = "NOT IN"
+		}
+		conditions = append(conditions, "id "+in+" (SELECT task_id FROM scheduler_deps WHERE "+blockingDep+")")
+	}
+	if len(f.Priorities) > 0 {
+		conditions = append(conditions, "priority IN ("+placeholders(len(f.Priorities))+")")
+		for _, p := range f.Priorities {
+			args = append(args, p)
+		}
+	}
+	return conditions, args
+}
+
+// queryCondition переводит поисковый запрос в условие WHERE с плейсхолдерами
+func (s *sqlStore) queryCondition(n *queryNode) (string, []interface{}) {
+	switch n.kind {
+	case queryAnd, queryOr:
+		op := " AND "
+		if n.kind == queryOr {
+			op = " OR "
+		}
+		parts := make([]string, len(n.children))
+		var args []interface{}
+		for i, child := range n.children {
+			var childArgs []interface{}
+			parts[i], childArgs = s.queryCondition(child)
+			args = append(args, childArgs...)
+		}
+		return "(" + strings.Join(parts, op) + ")", args
+
+	case queryNot:
+		condition, args := s.queryCondition(n.children[0])
+		return "NOT " + condition, args
+
+	case queryText:
+		if s.postgres {
+			vector := postgresSearchVector
+			switch n.term.field {
+			case "title":
+				vector = "to_tsvector('simple', title)"
+			case "comment":
+				vector = "to_tsvector('simple', coalesce(comment, ''))"
+			}
+			return "(" + vector + " @@ to_tsquery('simple', ?))", []interface{}{tsTerm(n.term)}
+		}
+		return "(id IN (SELECT rowid FROM scheduler_fts WHERE scheduler_fts MATCH ?))", []interface{}{ftsTerm(n.term)}
+
+	case queryDate:
+		var parts []string
+		var args []interface{}
+		if n.from != "" {
+			parts = append(parts, "date >= ?")
+			args = append(args, n.from)
+		}
+		if n.to != "" {
+			parts = append(parts, "date <= ?")
+			args = append(args, n.to)
+		}
+		return "(" + strings.Join(parts, " AND ") + ")", args
+
+	case queryTag:
+		args := make([]interface{}, len(n.tags))
+		for i, tag := range n.tags {
+			args[i] = tag
+		}
+		return `(id IN (SELECT st.task_id FROM scheduler_tags st
+			JOIN tags t ON t.id = st.tag_id WHERE t.name IN (` + placeholders(len(n.tags)) + `)))`, args
+
+	case queryRepeat:
+		if n.repeat {
+			return "(coalesce(repeat, '') <> '')", nil
+		}
+		return "(coalesce(repeat, '') = '')", nil
+	}
+	return "", nil
+}
+
+func (s *sqlStore) RescheduleTask(id, date, clock string) error {
+	_, err := s.exec("UPDATE scheduler SET date=?, time=?, anchor_date='' WHERE id=?", date, clock, id)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка обновления даты задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка обновления даты задачи с ID %s: %v", id, err)
+		return errors.New("ошибка обновления даты задачи")
+	}
+	return nil
+}
+
+// moveToOccurrence переносит задачу на следующее повторение next
+func (s *sqlStore) moveToOccurrence(id string, next *Occurrence) error {
+	_, err := s.exec("UPDATE scheduler SET date=?, time=?, repeat=?, anchor_date=? WHERE id=?",
+		next.Date, next.Time, next.Repeat, next.Anchor, id)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка обновления даты задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка обновления даты задачи с ID %s: %v", id, err)
+		return errors.New("ошибка обновления даты задачи")
+	}
+	return nil
+}
+
+func (s *sqlStore) SetTaskRepeat(id, repeat string) error {
+	_, err := s.exec("UPDATE scheduler SET repeat=? WHERE id=?", repeat, id)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка обновления правила повторения задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка обновления правила повторения задачи с ID %s: %v", id, err)
+		return errors.New("ошибка обновления правила повторения")
+	}
+	return nil
+}
+
+func (s *sqlStore) TrashTask(id string, completed bool) error {
+	column := "deleted_at"
+	if completed {
+		column = "completed_at"
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	_, err := s.exec("UPDATE scheduler SET "+column+" = ? WHERE id = ?", now, id)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка удаления задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка удаления задачи с ID %s: %v", id, err)
+		return errors.New("ошибка удаления задачи")
+	}
+	return nil
+}
+
+func (s *sqlStore) ListTrash(limit int, cursor *TaskCursor) ([]Task, error) {
+	const trashedAt = "CASE WHEN deleted_at > completed_at THEN deleted_at ELSE completed_at END"
+	conditions := []string{"NOT (" + activeTask + ")"}
+	var args []interface{}
+	if cursor != nil {
+		conditions = append(conditions, "("+trashedAt+", id) < (?, ?)")
+		args = append(args, cursor.TrashedAt, cursor.ID)
+	}
+
+	tasks := []Task{}
+	query := "SELECT " + taskColumns + " FROM scheduler WHERE " + strings.Join(conditions, " AND ") +
+		" ORDER BY " + trashedAt + " DESC, id DESC LIMIT ?"
+	if err := s.selectAll(&tasks, query, append(args, limit)...); err != nil {
+		logger.LogMessage("[ERROR] Ошибка получения корзины: " + err.Error())
+		log.Printf("Ошибка получения корзины: %v", err)
+		return nil, errors.New("ошибка получения корзины")
+	}
+	return tasks, nil
+}
+
+func (s *sqlStore) GetTrashedTask(id string) (*Task, error) {
+	numericID, err := parseTaskID(id)
+	if err != nil {
+		return nil, err
+	}
+	var task Task
+	err = s.get(&task, "SELECT "+taskColumns+" FROM scheduler WHERE id = ? AND NOT ("+activeTask+")", numericID)
+	if err != nil {
+		logger.LogMessage("[ERROR] Задача не найдена в корзине: " + id)
+		return nil, errors.New("задача не найдена в корзине")
+	}
+	return &task, nil
+}
+
+func (s *sqlStore) RestoreTask(id string) error {
+	_, err := s.exec("UPDATE scheduler SET deleted_at = '', completed_at = '' WHERE id = ?", id)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка восстановления задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка восстановления задачи с ID %s: %v", id, err)
+		return errors.New("ошибка восстановления задачи")
+	}
+	return nil
+}
+
+func (s *sqlStore) PurgeTask(id string) error {
+	return s.inTx(func(tx *sqlStore) error {
+		return tx.purgeTask(id)
+	})
+}
+
+func (s *sqlStore) purgeTask(id string) error {
+	_, err := s.exec("DELETE FROM scheduler WHERE id=?", id)
+	if err == nil {
+		_, err = s.exec("DELETE FROM scheduler_exdates WHERE task_id=?", id)
+	}
+	if err == nil {
+		_, err = s.exec("DELETE FROM scheduler_tags WHERE task_id=?", id)
+	}
+	if err == nil {
+		_, err = s.exec("DELETE FROM scheduler_checklist WHERE task_id=?", id)
+	}
+	if err == nil {
+		_, err = s.exec("DELETE FROM scheduler_deps WHERE task_id=? OR blocked_by=?", id, id)
+	}
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка удаления задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка удаления задачи с ID %s: %v", id, err)
+		return errors.New("ошибка удаления задачи")
+	}
+	return nil
+}
+
+// PurgeTrash удаляет задачи одной транзакцией: при ошибке корзина остаётся как была
+func (s *sqlStore) PurgeTrash(before time.Time) (purged int, err error) {
+	err = s.inTx(func(tx *sqlStore) error {
+		purged, err = tx.purgeTrash(before)
+		return err
+	})
+	return purged, err
+}
+
+func (s *sqlStore) purgeTrash(before time.Time) (int, error) {
+	var ids []string
+	var err error
+	if before.IsZero() {
+		err = s.selectAll(&ids, "SELECT id FROM scheduler WHERE NOT ("+activeTask+")")
+	} else {
+		// Время в корзине хранится в UTC в формате RFC 3339, поэтому строки сравниваются как время
+		at := before.UTC().Format(time.RFC3339)
+		err = s.selectAll(&ids, `SELECT id FROM scheduler
+			WHERE deleted_at != '' AND deleted_at < ? OR completed_at != '' AND completed_at < ?`, at, at)
+	}
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка очистки корзины: " + err.Error())
+		log.Printf("Ошибка очистки корзины: %v", err)
+		return 0, errors.New("ошибка очистки корзины")
+	}
+	for _, id := range ids {
+		if err := s.purgeTask(id); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), nil
+}
+
+func (s *sqlStore) AddExDate(id, date string) error {
+	_, err := s.exec("INSERT INTO scheduler_exdates (task_id, date) VALUES (?, ?) ON CONFLICT DO NOTHING", id, date)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка добавления исключения задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка добавления исключения задачи с ID %s: %v", id, err)
+		return errors.New("ошибка добавления исключения")
+	}
+	return nil
+}
+
+func (s *sqlStore) SkipOccurrence(id, date string, next *Occurrence) error {
+	return s.inTx(func(tx *sqlStore) error {
+		if err := tx.AddExDate(id, date); err != nil {
+			return err
+		}
+		if next == nil {
+			return nil
+		}
+		return tx.moveToOccurrence(id, next)
+	})
+}
+
+func (s *sqlStore) DeleteExDate(id, date string) error {
+	_, err := s.exec("DELETE FROM scheduler_exdates WHERE task_id = ? AND date = ?", id, date)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка удаления исключения задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка удаления исключения задачи с ID %s: %v", id, err)
+		return errors.New("ошибка удаления исключения")
+	}
+	return nil
+}
+
+func (s *sqlStore) getTaskExDates(id string) ([]string, error) {
+	var dates []string
+	err := s.selectAll(&dates, "SELECT date FROM scheduler_exdates WHERE task_id = ? ORDER BY date", id)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка получения исключений задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка получения исключений задачи с ID %s: %v", id, err)
+		return nil, errors.New("ошибка получения исключений задачи")
+	}
+	return dates, nil
+}
+
+func (s *sqlStore) ListTags() ([]Tag, error) {
+	tags := []Tag{}
+	err := s.selectAll(&tags, `SELECT t.id, t.name, count(s.id) AS tasks FROM tags t
+		LEFT JOIN scheduler_tags st ON st.tag_id = t.id
+		LEFT JOIN scheduler s ON s.id = st.task_id AND s.deleted_at = '' AND s.completed_at = ''
+		GROUP BY t.id, t.name ORDER BY t.name`)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка получения меток: " + err.Error())
+		log.Printf("Ошибка получения меток: %v", err)
+		return nil, errors.New("ошибка получения меток")
+	}
+	return tags, nil
+}
+
+func (s *sqlStore) CreateTag(name string) (int64, error) {
+	id, err := s.insert("INSERT INTO tags (name) VALUES (?)", name)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка создания метки " + name + ": " + err.Error())
+		log.Printf("Ошибка создания метки %s: %v", name, err)
+		return 0, errors.New("метка уже существует: " + name)
+	}
+	return id, nil
+}
+
+func (s *sqlStore) RenameTag(id, name string) error {
+	res, err := s.exec("UPDATE tags SET name = ? WHERE id = ?", name, id)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка переименования метки с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка переименования метки с ID %s: %v", id, err)
+		return errors.New("метка уже существует: " + name)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		logger.LogMessage("[ERROR] Метка не найдена")
+		return errors.New("метка не найдена")
+	}
+	return nil
+}
+
+func (s *sqlStore) DeleteTag(id string) error {
+	return s.inTx(func(tx *sqlStore) error {
+		return tx.deleteTag(id)
+	})
+}
+
+func (s *sqlStore) deleteTag(id string) error {
+	res, err := s.exec("DELETE FROM tags WHERE id = ?", id)
+	if err == nil {
+		if n, _ := res.RowsAffected(); n == 0 {
+			logger.LogMessage("[ERROR] Метка не найдена")
+			return errors.New("метка не найдена")
+		}
+		_, err = s.exec("DELETE FROM scheduler_tags WHERE tag_id = ?", id)
+	}
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка удаления метки с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка удаления метки с ID %s: %v", id, err)
+		return errors.New("ошибка удаления метки")
+	}
+	return nil
+}
+
+// setTaskTags заменяет метки задачи; недостающие метки создаются
+func (s *sqlStore) setTaskTags(id string, tags []string) error {
+	_, err := s.exec("DELETE FROM scheduler_tags WHERE task_id = ?", id)
+	for _, name := range tags {
+		if err != nil {
+			break
+		}
+		if _, err = s.exec("INSERT INTO tags (name) VALUES (?) ON CONFLICT DO NOTHING", name); err != nil {
+			break
+		}
+		_, err = s.exec(`INSERT INTO scheduler_tags (task_id, tag_id)
+			SELECT CAST(? AS INTEGER), id FROM tags WHERE name = ? ON CONFLICT DO NOTHING`, id, name)
+	}
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка сохранения меток задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка сохранения меток задачи с ID %s: %v", id, err)
+		return errors.New("ошибка сохранения меток задачи")
+	}
+	return nil
+}
+
+// loadTaskTags заполняет метки у списка задач одним запросом
+func (s *sqlStore) loadTaskTags(tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	index := make(map[string]*Task, len(tasks))
+	ids := make([]interface{}, len(tasks))
+	for i := range tasks {
+		index[tasks[i].ID] = &tasks[i]
+		ids[i] = tasks[i].ID
+	}
+
+	var rows []struct {
+		TaskID string `db:"task_id"`
+		Name   string `db:"name"`
+	}
+	query := `SELECT st.task_id, t.name FROM scheduler_tags st JOIN tags t ON t.id = st.tag_id
+		WHERE st.task_id IN (` + placeholders(len(ids)) + `) ORDER BY t.name`
+	if err := s.selectAll(&rows, query, ids...); err != nil {
+		logger.LogMessage("[ERROR] Ошибка получения меток задач: " + err.Error())
+		log.Printf("Ошибка получения меток задач: %v", err)
+		return errors.New("ошибка получения меток задач")
+	}
+	for _, row := range rows {
+		if task := index[row.TaskID]; task != nil {
+			task.Tags = append(task.Tags, row.Name)
+		}
+	}
+	return nil
+}
+
+func (s *sqlStore) ListProjects(archived bool) ([]Project, error) {
+	query := `SELECT p.id, p.name, p.color, p.archived, count(s.id) AS tasks FROM projects p
+		LEFT JOIN scheduler s ON s.project_id = p.id AND s.deleted_at = '' AND s.completed_at = ''`
+	if !archived {
+		query += " WHERE p.archived = 0"
+	}
+	query += " GROUP BY p.id, p.name, p.color, p.archived ORDER BY p.name"
+
+	projects := []Project{}
+	if err := s.selectAll(&projects, query); err != nil {
+		logger.LogMessage("[ERROR] Ошибка получения проектов: " + err.Error())
+		log.Printf("Ошибка получения проектов: %v", err)
+		return nil, errors.New("ошибка получения проектов")
+	}
+	return projects, nil
+}
+
+func (s *sqlStore) GetProject(id int64) (*Project, error) {
+	var project Project
+	err := s.get(&project, "SELECT id, name, color, archived FROM projects WHERE id = ?", id)
+	if err != nil {
+		logger.LogMessage("[ERROR] Проект не найден: " + strconv.FormatInt(id, 10))
+		return nil, errors.New("проект не найден")
+	}
+	return &project, nil
+}
+
+func (s *sqlStore) CreateProject(p *Project) (int64, error) {
+	id, err := s.insert("INSERT INTO projects (name, color, archived) VALUES (?, ?, ?)", p.Name, p.Color, boolInt(p.Archived))
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка создания проекта: " + err.Error())
+		log.Printf("Ошибка создания проекта: %v", err)
+		return 0, errors.New("ошибка создания проекта")
+	}
+	return id, nil
+}
+
+func (s *sqlStore) UpdateProject(p *Project) error {
+	res, err := s.exec("UPDATE projects SET name = ?, color = ?, archived = ? WHERE id = ?", p.Name, p.Color, boolInt(p.Archived), p.ID)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка обновления проекта с ID " + p.ID + ": " + err.Error())
+		log.Printf("Ошибка обновления проекта с ID %s: %v", p.ID, err)
+		return errors.New("ошибка обновления проекта")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		logger.LogMessage("[ERROR] Проект не найден")
+		return errors.New("проект не найден")
+	}
+	return nil
+}
+
+func (s *sqlStore) DeleteProject(id string) error {
+	return s.inTx(func(tx *sqlStore) error {
+		return tx.deleteProject(id)
+	})
+}
+
+func (s *sqlStore) deleteProject(id string) error {
+	res, err := s.exec("DELETE FROM projects WHERE id = ?", id)
+	if err == nil {
+		if n, _ := res.RowsAffected(); n == 0 {
+			logger.LogMessage("[ERROR] Проект не найден")
+			return errors.New("проект не найден")
+		}
+		_, err = s.exec("UPDATE scheduler SET project_id = NULL WHERE project_id = ?", id)
+	}
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка удаления проекта с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка удаления проекта с ID %s: %v", id, err)
+		return errors.New("ошибка удаления проекта")
+	}
+	return nil
+}
+
+func (s *sqlStore) getChecklist(id string) ([]ChecklistItem, error) {
+	var items []ChecklistItem
+	err := s.selectAll(&items, "SELECT id, title, done FROM scheduler_checklist WHERE task_id = ? ORDER BY position, id", id)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка получения чек-листа задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка получения чек-листа задачи с ID %s: %v", id, err)
+		return nil, errors.New("ошибка получения чек-листа задачи")
+	}
+	return items, nil
+}
+
+// setChecklist заменяет чек-лист задачи пунктами items
+func (s *sqlStore) setChecklist(id string, items []ChecklistItem) error {
+	_, err := s.exec("DELETE FROM scheduler_checklist WHERE task_id = ?", id)
+	for position, item := range items {
+		if err != nil {
+			break
+		}
+		_, err = s.exec("INSERT INTO scheduler_checklist (task_id, title, done, position) VALUES (?, ?, ?, ?)",
+			id, item.Title, boolInt(item.Done), position)
+	}
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка сохранения чек-листа задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка сохранения чек-листа задачи с ID %s: %v", id, err)
+		return errors.New("ошибка сохранения чек-листа задачи")
+	}
+	return nil
+}
+
+func (s *sqlStore) AddChecklistItem(id string, item ChecklistItem, position int) (int64, error) {
+	itemID, err := s.insert("INSERT INTO scheduler_checklist (task_id, title, done, position) VALUES (?, ?, ?, ?)",
+		id, item.Title, boolInt(item.Done), position)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка добавления пункта чек-листа задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка добавления пункта чек-листа задачи с ID %s: %v", id, err)
+		return 0, errors.New("ошибка добавления пункта чек-листа")
+	}
+	return itemID, nil
+}
+
+func (s *sqlStore) UpdateChecklistItem(id string, item ChecklistItem) error {
+	res, err := s.exec("UPDATE scheduler_checklist SET title = ?, done = ? WHERE id = ? AND task_id = ?",
+		item.Title, boolInt(item.Done), item.ID, id)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка обновления пункта чек-листа задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка обновления пункта чек-листа задачи с ID %s: %v", id, err)
+		return errors.New("ошибка обновления пункта чек-листа")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		logger.LogMessage("[ERROR] Пункт чек-листа не найден")
+		return errors.New("пункт чек-листа не найден")
+	}
+	return nil
+}
+
+func (s *sqlStore) DeleteChecklistItem(id, itemID string) error {
+	res, err := s.exec("DELETE FROM scheduler_checklist WHERE id = ? AND task_id = ?", itemID, id)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка удаления пункта чек-листа задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка удаления пункта чек-листа задачи с ID %s: %v", id, err)
+		return errors.New("ошибка удаления пункта чек-листа")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		logger.LogMessage("[ERROR] Пункт чек-листа не найден")
+		return errors.New("пункт чек-листа не найден")
+	}
+	return nil
+}
+
+func (s *sqlStore) ResetChecklist(id string) error {
+	_, err := s.exec("UPDATE scheduler_checklist SET done = 0 WHERE task_id = ?", id)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка сброса чек-листа задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка сброса чек-листа задачи с ID %s: %v", id, err)
+		return errors.New("ошибка сброса чек-листа задачи")
+	}
+	return nil
+}
+
+func (s *sqlStore) CompleteChecklist(id string) error {
+	_, err := s.exec("UPDATE scheduler_checklist SET done = 1 WHERE task_id = ?", id)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка отметки чек-листа задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка отметки чек-листа задачи с ID %s: %v", id, err)
+		return errors.New("ошибка отметки чек-листа задачи")
+	}
+	return nil
+}
+
+func (s *sqlStore) AddDependency(id, blocker string) error {
+	if id == blocker {
+		logger.LogMessage("[ERROR] Задача не может блокировать саму себя")
+		return errors.New("задача не может блокировать саму себя")
+	}
+
+	// Цикл возникает, если blocker уже прямо или через другие задачи ждёт id
+	var cycle int
+	err := s.get(&cycle, `WITH RECURSIVE chain(id) AS (
+			SELECT CAST(? AS INTEGER)
+			UNION
+			SELECT d.blocked_by FROM scheduler_deps d JOIN chain c ON d.task_id = c.id
+		)
+		SELECT count(*) FROM chain WHERE id = CAST(? AS INTEGER)`, blocker, id)
+	if err == nil && cycle > 0 {
+		logger.LogMessage("[ERROR] Зависимость образует цикл: " + id + " <- " + blocker)
+		return errors.New("зависимость образует цикл")
+	}
+	if err == nil {
+		_, err = s.exec(`INSERT INTO scheduler_deps (task_id, blocked_by) VALUES (?, ?)
+			ON CONFLICT (task_id, blocked_by) DO UPDATE SET resolved = 0`, id, blocker)
+	}
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка добавления зависимости задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка добавления зависимости задачи с ID %s: %v", id, err)
+		return errors.New("ошибка добавления зависимости")
+	}
+	return nil
+}
+
+func (s *sqlStore) DeleteDependency(id, blocker string) error {
+	_, err := s.exec("DELETE FROM scheduler_deps WHERE task_id = ? AND blocked_by = ?", id, blocker)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка удаления зависимости задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка удаления зависимости задачи с ID %s: %v", id, err)
+		return errors.New("ошибка удаления зависимости")
+	}
+	return nil
+}
+
+func (s *sqlStore) UnblockDependents(id string) error {
+	_, err := s.exec("UPDATE scheduler_deps SET resolved = 1 WHERE blocked_by = ?", id)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка снятия блокировок задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка снятия блокировок задачи с ID %s: %v", id, err)
+		return errors.New("ошибка снятия блокировок задачи")
+	}
+	return nil
+}
+
+func (s *sqlStore) RearmDependencies(id string) error {
+	_, err := s.exec("UPDATE scheduler_deps SET resolved = 0 WHERE task_id = ?", id)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка восстановления блокировок задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка восстановления блокировок задачи с ID %s: %v", id, err)
+		return errors.New("ошибка восстановления блокировок задачи")
+	}
+	return nil
+}
+
+// loadDependencies заполняет у задачи списки BlockedBy и Blocking; снятые зависимости
+// и задачи в корзине в них не входят
+func (s *sqlStore) loadDependencies(task *Task) error {
+	err := s.selectAll(&task.BlockedBy, `SELECT blocked_by FROM scheduler_deps
+		WHERE task_id = ? AND `+blockingDep+` ORDER BY blocked_by`, task.ID)
+	if err == nil {
+		err = s.selectAll(&task.Blocking, `SELECT task_id FROM scheduler_deps
+			WHERE blocked_by = ? AND resolved = 0 AND task_id IN (`+activeTaskIDs+`) ORDER BY task_id`, task.ID)
+	}
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка получения зависимостей задачи с ID " + task.ID + ": " + err.Error())
+		log.Printf("Ошибка получения зависимостей задачи с ID %s: %v", task.ID, err)
+		return errors.New("ошибка получения зависимостей задачи")
+	}
+	task.Blocked = len(task.BlockedBy) > 0
+	return nil
+}
+
+// markBlocked отмечает в списке задачи, которые ждут выполнения других задач
+func (s *sqlStore) markBlocked(tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	index := make(map[string]*Task, len(tasks))
+	ids := make([]interface{}, len(tasks))
+	for i := range tasks {
+		index[tasks[i].ID] = &tasks[i]
+		ids[i] = tasks[i].ID
+	}
+
+	var blocked []string
+	query := "SELECT DISTINCT task_id FROM scheduler_deps WHERE task_id IN (" + placeholders(len(ids)) + ") AND " + blockingDep
+	if err := s.selectAll(&blocked, query, ids...); err != nil {
+		logger.LogMessage("[ERROR] Ошибка получения зависимостей задач: " + err.Error())
+		log.Printf("Ошибка получения зависимостей задач: %v", err)
+		return errors.New("ошибка получения зависимостей задач")
+	}
+	for _, id := range blocked {
+		if task := index[id]; task != nil {
+			task.Blocked = true
+		}
+	}
+	return nil
+}
+
+func (s *sqlStore) AddCompletion(id string, c Completion) error {
+	_, err := s.exec(`INSERT INTO scheduler_completions (task_id, scheduled_date, scheduled_time, completed_at, note)
+		VALUES (?, ?, ?, ?, ?)`, id, c.ScheduledDate, c.ScheduledTime, c.CompletedAt, c.Note)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка записи выполнения задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка записи выполнения задачи с ID %s: %v", id, err)
+		return errors.New("ошибка записи выполнения задачи")
+	}
+	return nil
+}
+
+func (s *sqlStore) CompleteOccurrence(id string, c Completion, next *Occurrence, cascade bool) error {
+	return s.inTx(func(tx *sqlStore) error {
+		if err := tx.AddCompletion(id, c); err != nil {
+			return err
+		}
+		if cascade {
+			if err := tx.CompleteChecklist(id); err != nil {
+				return err
+			}
+		}
+		if err := tx.UnblockDependents(id); err != nil {
+			return err
+		}
+		if next == nil {
+			return tx.TrashTask(id, true)
+		}
+		if err := tx.moveToOccurrence(id, next); err != nil {
+			return err
+		}
+		if err := tx.ResetChecklist(id); err != nil {
+			return err
+		}
+		return tx.RearmDependencies(id)
+	})
+}
+
+func (s *sqlStore) ListCompletions(id string) ([]Completion, error) {
+	history := []Completion{}
+	err := s.selectAll(&history, `SELECT id, scheduled_date, scheduled_time, completed_at, note
+		FROM scheduler_completions WHERE task_id = ? ORDER BY id DESC`, id)
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка получения истории задачи с ID " + id + ": " + err.Error())
+		log.Printf("Ошибка получения истории задачи с ID %s: %v", id, err)
+		return nil, errors.New("ошибка получения истории задачи")
+	}
+	return history, nil
+}
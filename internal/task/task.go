@@ -1,17 +1,19 @@
 package task
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"go_final_project/internal/logger"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"go_final_project/internal"
 	"go_final_project/internal/scheduler"
-
-	"github.com/jmoiron/sqlx"
 )
 
 // Task описывает задачу
@@ -21,25 +23,107 @@ type Task struct {
 	Title   string `db:"title" json:"title"`
 	Comment string `db:"comment" json:"comment,omitempty"`
 	Repeat  string `db:"repeat" json:"repeat,omitempty"`
-}
+	// AnchorDate — дата задачи по графику повторений до переноса roll, пустая — совпадает с Date
+	AnchorDate string `db:"anchor_date" json:"-"`
+	// Time — необязательное время задачи в формате HH:MM
+	Time string `db:"time" json:"time,omitempty"`
+	// Timezone — часовой пояс задачи (IANA), пустой — пояс сервера по умолчанию
+	Timezone string `db:"timezone" json:"timezone,omitempty"`
+	// Priority — приоритет задачи от 1 (срочно) до 4 (обычный), у новой задачи по умолчанию 4;
+	// при редактировании отсутствие поля оставляет приоритет без изменений
+	Priority int `db:"priority" json:"priority,string,omitempty"`
+	// ProjectID — проект задачи, nil — задача вне проектов
+	ProjectID *int64 `db:"project_id" json:"project_id,string,omitempty"`
+	// DeletedAt и CompletedAt — время (RFC 3339, UTC), когда задача была удалена
+	// или выполнена и попала в корзину; у активных задач пустые
+	DeletedAt   string `db:"deleted_at" json:"deleted_at,omitempty"`
+	CompletedAt string `db:"completed_at" json:"completed_at,omitempty"`
+	// Tags — метки задачи; при редактировании отсутствие поля оставляет метки без изменений
+	Tags []string `db:"-" json:"tags,omitempty"`
+	// Due — срок задачи в формате ISO 8601, вычисляется при выдаче задачи
+	Due string `db:"-" json:"due,omitempty"`
+	// RepeatText — описание правила повторения, вычисляется при выдаче задачи
+	RepeatText string `db:"-" json:"repeat_text,omitempty"`
+	// Checklist — пункты чек-листа; при редактировании отсутствие поля оставляет чек-лист без изменений
+	Checklist []ChecklistItem `db:"-" json:"checklist,omitempty"`
+	// BlockedBy и Blocking — задачи, которые блокируют эту задачу и которые блокирует она
+	BlockedBy []string `db:"-" json:"blocked_by,omitempty"`
+	Blocking  []string `db:"-" json:"blocking,omitempty"`
+	// Blocked — задача ждёт выполнения других задач
+	Blocked bool `db:"-" json:"blocked,omitempty"`
+	// ExDates — даты-исключения повторяющейся задачи в формате YYYYMMDD
+	ExDates []string `db:"-" json:"exdates,omitempty"`
+	// Snippet — фрагмент заголовка или комментария с найденными словами,
+	// выделенными тегами <mark>; остальной текст экранирован для HTML.
+	// Заполняется только при поиске по тексту
+	Snippet string `db:"snippet" json:"snippet,omitempty"`
 
-type Repository struct {
-	db *sqlx.DB
+	// rule — разобранное правило повторения, ruleRepeat — строка, из которой оно получено
+	rule       *scheduler.Rule
+	ruleRepeat string
 }
 
-func NewTaskRepository(db *sqlx.DB) *Repository {
-	return &Repository{db: db}
+var (
+	errPriorityJSON = errors.New("приоритет задачи должен быть числом или строкой с числом")
+	errProjectJSON  = errors.New("проект задачи должен быть числом или строкой с числом")
+)
+
+// UnmarshalJSON разбирает задачу, принимая приоритет и проект и строкой ("1"), и числом (1)
+func (t *Task) UnmarshalJSON(data []byte) error {
+	type plainTask Task
+	fields := struct {
+		*plainTask
+		Priority  json.RawMessage `json:"priority"`
+		ProjectID json.RawMessage `json:"project_id"`
+	}{plainTask: (*plainTask)(t)}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	priority, ok, err := parseJSONNumber(fields.Priority)
+	if err != nil {
+		return errPriorityJSON
+	}
+	if ok {
+		t.Priority = int(priority)
+	}
+	projectID, ok, err := parseJSONNumber(fields.ProjectID)
+	if err != nil {
+		return errProjectJSON
+	}
+	if ok {
+		t.ProjectID = &projectID
+	}
+	return nil
 }
 
-func (r *Repository) Save(t *Task) (int64, error) {
-	query := `INSERT INTO scheduler (date, title, comment, repeat) VALUES (?, ?, ?, ?)`
-	res, err := r.db.Exec(query, t.Date, t.Title, t.Comment, t.Repeat)
+// parseJSONNumber разбирает целое число, записанное в JSON числом или строкой;
+// ok ложно, если значения нет или оно null
+func parseJSONNumber(raw json.RawMessage) (n int64, ok bool, err error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, false, nil
+	}
+	var str string
+	if raw[0] == '"' {
+		if err := json.Unmarshal(raw, &str); err != nil {
+			return 0, false, err
+		}
+	} else {
+		str = string(raw)
+	}
+	n, err = strconv.ParseInt(str, 10, 64)
 	if err != nil {
-		logger.LogMessage("[ERROR] Ошибка SQL: " + err.Error())
-		log.Println("Ошибка SQL:", err)
-		return 0, errors.New("ошибка сохранения в БД")
+		return 0, false, err
+	}
+	return n, true, nil
+}
+
+// taskJSONError возвращает текст ошибки разбора задачи для ответа клиенту
+func taskJSONError(err error) string {
+	if errors.Is(err, errPriorityJSON) || errors.Is(err, errProjectJSON) {
+		return err.Error()
 	}
-	return res.LastInsertId()
+	return "ошибка разбора JSON"
 }
 
 func (t *Task) Validate() error {
@@ -47,18 +131,122 @@ func (t *Task) Validate() error {
 		logger.LogMessage("[ERROR] Не указан заголовок задачи")
 		return errors.New("не указан заголовок задачи")
 	}
+	if t.Priority < internal.HighestPriority || t.Priority > internal.LowestPriority {
+		logger.LogMessage("[ERROR] Приоритет задачи должен быть от 1 до 4")
+		return errors.New("приоритет задачи должен быть от 1 до 4")
+	}
+	tags, err := normalizeTags(t.Tags)
+	if err != nil {
+		return err
+	}
+	t.Tags = tags
+	for i := range t.Checklist {
+		if err := t.Checklist[i].Validate(); err != nil {
+			return err
+		}
+	}
+	loc, err := t.Location()
+	if err != nil {
+		return err
+	}
+	if t.Time != "" {
+		if _, err := time.Parse(internal.TimeLayout, t.Time); err != nil {
+			logger.LogMessage("[ERROR] Время указано в неверном формате HH:MM")
+			return errors.New("время указано в неверном формате HH:MM")
+		}
+	}
 	if t.Date == "" {
-		t.Date = time.Now().Format(internal.DateLayout)
+		t.Date = scheduler.Now(loc).Format(internal.DateLayout)
 	}
 	if _, err := time.Parse(internal.DateLayout, t.Date); err != nil {
 		logger.LogMessage("[ERROR] Дата указана в неверном формате YYYYMMDD")
 		return errors.New("дата указана в неверном формате YYYYMMDD")
 	}
+	if len([]rune(t.Repeat)) > internal.MaxRepeatLength {
+		logger.LogMessage("[ERROR] Правило повторения слишком длинное")
+		return fmt.Errorf("правило повторения длиннее %d символов", internal.MaxRepeatLength)
+	}
+	if t.Repeat != "" {
+		if _, err := t.Rule(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// Location возвращает часовой пояс задачи
+func (t *Task) Location() (*time.Location, error) {
+	return scheduler.LoadLocation(t.Timezone)
+}
+
+// setDue заполняет Due: дату и время задачи в её часовом поясе в формате ISO 8601
+func (t *Task) setDue() {
+	loc, err := t.Location()
+	if err != nil {
+		return
+	}
+	date, err := time.Parse(internal.DateLayout, t.Date)
+	if err != nil {
+		return
+	}
+	if t.Time == "" {
+		t.Due = date.Format(internal.ISODateLayout)
+		return
+	}
+	clock, err := time.Parse(internal.TimeLayout, t.Time)
+	if err != nil {
+		return
+	}
+	due := time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+	t.Due = due.Format(time.RFC3339)
+}
+
+// Rule возвращает разобранное правило повторения задачи с датой начала t.Date,
+// а для правил h и min — с датой и временем t.Time. Правило разбирается один раз и переиспользуется, пока не изменится t.Repeat.
+func (t *Task) Rule() (*scheduler.Rule, error) {
+	if t.rule == nil || t.ruleRepeat != t.Repeat {
+		rule, err := scheduler.ParseRule(t.Repeat)
+		if err != nil {
+			return nil, err
+		}
+		t.rule, t.ruleRepeat = rule, t.Repeat
+	}
+
+	start, err := time.Parse(internal.DateLayout, t.Date)
+	if err != nil {
+		logger.LogMessage("[ERROR] Дата указана в неверном формате YYYYMMDD")
+		return nil, errors.New("дата указана в неверном формате YYYYMMDD")
+	}
+	if t.rule.SubDaily() && t.Time != "" {
+		clock, err := time.Parse(internal.TimeLayout, t.Time)
+		if err != nil {
+			logger.LogMessage("[ERROR] Время указано в неверном формате HH:MM")
+			return nil, errors.New("время указано в неверном формате HH:MM")
+		}
+		start = start.Add(time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute)
+	}
+	t.rule.Start = start
+	t.rule.Anchor = time.Time{}
+	if t.AnchorDate != "" {
+		if t.rule.Anchor, err = time.Parse(internal.DateLayout, t.AnchorDate); err != nil {
+			logger.LogMessage("[ERROR] Дата по графику указана в неверном формате YYYYMMDD")
+			return nil, errors.New("дата по графику указана в неверном формате YYYYMMDD")
+		}
+	}
+	t.rule.Exclude = make(map[string]bool, len(t.ExDates))
+	for _, date := range t.ExDates {
+		t.rule.Exclude[date] = true
+	}
+	return t.rule, nil
+}
+
 func (t *Task) AdjustDate() error {
-	todayStr := time.Now().Format(internal.DateLayout)
+	loc, err := t.Location()
+	if err != nil {
+		return err
+	}
+	now := scheduler.Now(loc)
+	todayStr := now.Format(internal.DateLayout)
 	// Проверяем, является ли дата задачи прошлой
 	if t.Date < todayStr {
 		// Если повторения нет, просто ставим сегодняшнюю дату
@@ -71,24 +259,38 @@ func (t *Task) AdjustDate() error {
 				return errors.New("ошибка обработки текущей даты")
 			}
 
-			nextDate, err := scheduler.NextDate(currentDate, t.Date, t.Repeat)
+			rule, err := t.Rule()
+			if err != nil {
+				return err
+			}
+			// Правила h и min переносятся на ближайший слот после текущего момента
+			if rule.SubDaily() {
+				currentDate = now
+			}
+			nextDate, scheduled, err := rule.NextScheduled(currentDate)
+			if errors.Is(err, scheduler.ErrNoMoreOccurrences) {
+				logger.LogMessage("[ERROR] Серия повторений уже завершена")
+				return err
+			}
 			if err != nil {
 				logger.LogMessage("[ERROR] Ошибка в правиле повторения")
 				return errors.New("ошибка в правиле повторения")
 			}
-			t.Date = nextDate
+			t.Date = nextDate.Format(internal.DateLayout)
+			t.AnchorDate = anchorDate(nextDate, scheduled)
+			if rule.SubDaily() {
+				t.Time = nextDate.Format(internal.TimeLayout)
+			}
 		}
 	}
 	return nil
 }
 
-func AddTaskHandler(db *sqlx.DB) http.HandlerFunc {
-	repo := NewTaskRepository(db)
-
+func AddTaskHandler(store TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
-			addTask(w, r, repo)
+			addTask(w, r, store)
 		default:
 			logger.LogMessage("[ERROR] Метод не поддерживается")
 			http.Error(w, `{"error":"метод не поддерживается"}`, http.StatusMethodNotAllowed)
@@ -96,14 +298,19 @@ func AddTaskHandler(db *sqlx.DB) http.HandlerFunc {
 	}
 }
 
-func addTask(w http.ResponseWriter, r *http.Request, repo *Repository) {
+func addTask(w http.ResponseWriter, r *http.Request, store TaskStore) {
 	var task Task
 	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
-		logger.LogMessage("[ERROR] Ошибка разбора JSON")
-		http.Error(w, `{"error":"ошибка разбора JSON"}`, http.StatusBadRequest)
+		logger.LogMessage("[ERROR] Ошибка разбора JSON: " + err.Error())
+		http.Error(w, `{"error":"`+taskJSONError(err)+`"}`, http.StatusBadRequest)
 		return
 	}
 
+	// Приоритет по умолчанию назначается только новой задаче
+	if task.Priority == 0 {
+		task.Priority = internal.LowestPriority
+	}
+
 	// Валидируем поля задачи
 	if err := task.Validate(); err != nil {
 		logger.LogMessage("[ERROR] " + err.Error())
@@ -111,6 +318,11 @@ func addTask(w http.ResponseWriter, r *http.Request, repo *Repository) {
 		return
 	}
 
+	if err := resolveProject(store, &task, nil); err != nil {
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
 	// Корректируем дату, если нужно
 	if err := task.AdjustDate(); err != nil {
 		logger.LogMessage("[ERROR] " + err.Error())
@@ -118,8 +330,8 @@ func addTask(w http.ResponseWriter, r *http.Request, repo *Repository) {
 		return
 	}
 
-	// Сохраняем в БД (через репозиторий)
-	id, err := repo.Save(&task)
+	// Сохраняем в хранилище
+	id, err := store.AddTask(&task)
 	if err != nil {
 		logger.LogMessage("[ERROR] Ошибка сохранения в БД")
 		log.Println("Ошибка сохранения в БД:", err)
@@ -133,11 +345,11 @@ func addTask(w http.ResponseWriter, r *http.Request, repo *Repository) {
 	json.NewEncoder(w).Encode(map[string]interface{}{"id": id})
 }
 
-func GetTasksHandler(db *sqlx.DB) http.HandlerFunc {
+func GetTasksHandler(store TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			getTasks(w, r, db)
+			getTasks(w, r, store)
 		default:
 			logger.LogMessage("[ERROR] Метод не поддерживается")
 			http.Error(w, `{"error":"метод не поддерживается"}`, http.StatusMethodNotAllowed)
@@ -145,46 +357,192 @@ func GetTasksHandler(db *sqlx.DB) http.HandlerFunc {
 	}
 }
 
-func getTasks(w http.ResponseWriter, r *http.Request, db *sqlx.DB) {
-	search := r.URL.Query().Get("search")
-	limit := internal.TaskLimit
+func getTasks(w http.ResponseWriter, r *http.Request, store TaskStore) {
+	// Поисковый запрос: текст, даты, сроки, метки и их сочетания (см. ParseQuery)
+	query, err := ParseQuery(r.URL.Query().Get("search"), scheduler.Now(scheduler.DefaultLocation()))
+	if err != nil {
+		logger.LogMessage("[ERROR] " + err.Error())
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+	filter := TaskFilter{Query: query}
+
+	// Фильтр по проекту: project=0 — задачи вне проектов. Без фильтра
+	// задачи архивных проектов не показываются.
+	if projectStr := r.URL.Query().Get("project"); projectStr != "" {
+		projectID, err := strconv.ParseInt(projectStr, 10, 64)
+		if err != nil {
+			logger.LogMessage("[ERROR] Некорректный параметр 'project': " + projectStr)
+			http.Error(w, `{"error":"некорректный параметр 'project'"}`, http.StatusBadRequest)
+			return
+		}
+		filter.ProjectID = &projectID
+	}
+
+	// Фильтр по блокировке: blocked=0 скрывает заблокированные задачи, blocked=1 оставляет только их
+	switch blockedStr := r.URL.Query().Get("blocked"); blockedStr {
+	case "":
+	case "0", "1":
+		blocked := blockedStr == "1"
+		filter.Blocked = &blocked
+	default:
+		logger.LogMessage("[ERROR] Некорректный параметр 'blocked': " + blockedStr)
+		http.Error(w, `{"error":"некорректный параметр 'blocked'"}`, http.StatusBadRequest)
+		return
+	}
 
-	var query string
-	var args []interface{}
+	// Фильтр по приоритетам: priority=1 или priority=1,2
+	if priorityStr := r.URL.Query().Get("priority"); priorityStr != "" {
+		priorities, err := parsePriorities(priorityStr)
+		if err != nil {
+			logger.LogMessage("[ERROR] " + err.Error())
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+		filter.Priorities = priorities
+	}
 
-	// Если search соответствует формату даты "DD.MM.YYYY"
-	if isValidDateFormat(search) {
-		dateFilter := convertToDBDateFormat(search)
-		query = "SELECT id, date, title, comment, repeat FROM scheduler WHERE date = ? ORDER BY date LIMIT ?"
-		args = append(args, dateFilter, limit)
-	} else if search != "" {
-		// Ищем по строкам title и comment
-		query = "SELECT id, date, title, comment, repeat FROM scheduler WHERE title LIKE ? OR comment LIKE ? ORDER BY date LIMIT ?"
-		args = append(args, "%"+search+"%", "%"+search+"%", limit)
-	} else {
-		// Если search пустой, возвращаем все задачи, отсортированные по дате
-		query = "SELECT id, date, title, comment, repeat FROM scheduler ORDER BY date LIMIT ?"
-		args = append(args, limit)
+	if filter.Limit, err = pageLimit(r); err != nil {
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	// Следующая страница: cursor — значение next_cursor из предыдущего ответа
+	ranked := len(query.rankTerms()) > 0
+	if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
+		cursor, err := decodeCursor(cursorStr, ranked)
+		if err != nil {
+			logger.LogMessage("[ERROR] Некорректный параметр 'cursor': " + cursorStr)
+			http.Error(w, `{"error":"некорректный параметр 'cursor'"}`, http.StatusBadRequest)
+			return
+		}
+		filter.Cursor = cursor
+	}
+
+	// Общее число задач по фильтру: total=1
+	withTotal := false
+	switch totalStr := r.URL.Query().Get("total"); totalStr {
+	case "", "0":
+	case "1":
+		withTotal = true
+	default:
+		logger.LogMessage("[ERROR] Некорректный параметр 'total': " + totalStr)
+		http.Error(w, `{"error":"некорректный параметр 'total'"}`, http.StatusBadRequest)
+		return
 	}
 
-	var tasks []Task
-	err := db.Select(&tasks, query, args...)
+	// Лишняя задача в выборке показывает, что за страницей есть ещё задачи
+	page := filter
+	page.Limit++
+	tasks, err := store.ListTasks(page)
 	if err != nil {
-		logger.LogMessage("[ERROR] Ошибка при извлечении данных")
-		http.Error(w, `{"error":"ошибка при извлечении данных"}`, http.StatusInternalServerError)
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
+	response := map[string]interface{}{}
+	if len(tasks) > filter.Limit {
+		tasks = tasks[:filter.Limit]
+		response["next_cursor"] = encodeCursor(filter.Cursor, tasks, ranked)
+	}
+	for i := range tasks {
+		tasks[i].setDue()
+	}
+	response["tasks"] = tasks
+
+	if withTotal {
+		total, err := store.CountTasks(filter)
+		if err != nil {
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+			return
+		}
+		response["total"] = strconv.Itoa(total)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if len(tasks) == 0 {
-		tasks = []Task{} // Чтобы в JSON был пустой массив, а не null
+	json.NewEncoder(w).Encode(response)
+}
+
+// pageLimit возвращает размер страницы из параметра limit, по умолчанию internal.TaskLimit
+func pageLimit(r *http.Request) (int, error) {
+	limitStr := r.URL.Query().Get("limit")
+	if limitStr == "" {
+		return internal.TaskLimit, nil
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > internal.MaxTaskLimit {
+		logger.LogMessage("[ERROR] Некорректный параметр 'limit': " + limitStr)
+		return 0, errors.New("некорректный параметр 'limit'")
 	}
+	return limit, nil
+}
 
-	response := map[string]interface{}{
-		"tasks": tasks,
+// encodeCursor возвращает непрозрачную позицию после последней задачи страницы tasks,
+// которая была выбрана с позиции prev. Задачи в порядке релевантности не упорядочены
+// по своим полям, поэтому для них позиция — число выданных задач.
+func encodeCursor(prev *TaskCursor, tasks []Task, ranked bool) string {
+	var cursor TaskCursor
+	if ranked {
+		cursor.Offset = len(tasks)
+		if prev != nil {
+			cursor.Offset += prev.Offset
+		}
+	} else {
+		last := tasks[len(tasks)-1]
+		cursor.Date, cursor.Priority = last.Date, last.Priority
+		cursor.ID, _ = strconv.ParseInt(last.ID, 10, 64)
 	}
+	return marshalCursor(cursor)
+}
 
-	json.NewEncoder(w).Encode(response)
+// marshalCursor кодирует позицию в строку для параметра cursor
+func marshalCursor(cursor TaskCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// unmarshalCursor разбирает строку, полученную от marshalCursor
+func unmarshalCursor(str string) (*TaskCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(str)
+	if err != nil {
+		return nil, err
+	}
+	var cursor TaskCursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// decodeCursor разбирает позицию, выданную encodeCursor для списка того же вида
+func decodeCursor(str string, ranked bool) (*TaskCursor, error) {
+	cursor, err := unmarshalCursor(str)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.TrashedAt != "" ||
+		ranked && (cursor.ID != 0 || cursor.Offset <= 0) ||
+		!ranked && (cursor.ID <= 0 || cursor.Offset != 0) {
+		return nil, errors.New("позиция не подходит к списку задач")
+	}
+	if !ranked {
+		if _, err = time.Parse(internal.DateLayout, cursor.Date); err != nil {
+			return nil, err
+		}
+	}
+	return cursor, nil
+}
+
+// parsePriorities разбирает список приоритетов через запятую
+func parsePriorities(str string) ([]int, error) {
+	var priorities []int
+	for _, item := range strings.Split(str, ",") {
+		p, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil || p < internal.HighestPriority || p > internal.LowestPriority {
+			return nil, errors.New("некорректный параметр 'priority'")
+		}
+		priorities = append(priorities, p)
+	}
+	return priorities, nil
 }
 
 // isValidDateFormat проверяет, соответствует ли строка формату "DD.MM.YYYY"
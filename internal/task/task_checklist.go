@@ -0,0 +1,150 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go_final_project/internal/logger"
+)
+
+// Политики выполнения задачи с невыполненными пунктами чек-листа
+const (
+	// DonePolicyRefuse — задача не выполняется, пока не отмечены все пункты
+	DonePolicyRefuse = "refuse"
+	// DonePolicyCascade — выполнение задачи отмечает все её пункты
+	DonePolicyCascade = "cascade"
+)
+
+// donePolicy — политика выполнения по умолчанию
+var donePolicy = DonePolicyRefuse
+
+// ChecklistItem — пункт чек-листа задачи
+type ChecklistItem struct {
+	ID    string `db:"id" json:"id"`
+	Title string `db:"title" json:"title"`
+	Done  bool   `db:"done" json:"done"`
+}
+
+// InitDonePolicy задаёт политику выполнения задач с чек-листом по умолчанию.
+// Пустая строка оставляет DonePolicyRefuse.
+func InitDonePolicy(policy string) error {
+	if policy == "" {
+		return nil
+	}
+	if policy != DonePolicyRefuse && policy != DonePolicyCascade {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Неизвестная политика выполнения задач: %s", policy))
+		return fmt.Errorf("неизвестная политика выполнения задач: %s", policy)
+	}
+	donePolicy = policy
+	logger.LogMessage(fmt.Sprintf("[INFO] Политика выполнения задач с чек-листом: %s", policy))
+	return nil
+}
+
+// ChecklistHandler управляет чек-листом задачи id: GET возвращает пункты,
+// POST добавляет пункт, PUT изменяет название и отметку пункта,
+// DELETE удаляет пункт item.
+func ChecklistHandler(store TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			logger.LogMessage("[ERROR] Не указан идентификатор задачи")
+			http.Error(w, `{"error":"не указан идентификатор задачи"}`, http.StatusBadRequest)
+			return
+		}
+
+		task, err := store.GetTask(id)
+		if err != nil {
+			logger.LogMessage("[ERROR] Задача не найдена")
+			http.Error(w, `{"error":"задача не найдена"}`, http.StatusNotFound)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"checklist": nonNilItems(task.Checklist)})
+			return
+
+		case http.MethodPost, http.MethodPut:
+			var item ChecklistItem
+			if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+				logger.LogMessage("[ERROR] Ошибка разбора JSON")
+				http.Error(w, `{"error":"ошибка разбора JSON"}`, http.StatusBadRequest)
+				return
+			}
+			if err := item.Validate(); err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+				return
+			}
+
+			if r.Method == http.MethodPost {
+				itemID, err := store.AddChecklistItem(id, item, len(task.Checklist))
+				if err != nil {
+					http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]interface{}{"id": itemID})
+				return
+			}
+
+			if err := updateChecklistItem(store, id, item); err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+				return
+			}
+
+		case http.MethodDelete:
+			if err := store.DeleteChecklistItem(id, r.URL.Query().Get("item")); err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+				return
+			}
+
+		default:
+			logger.LogMessage("[ERROR] Метод не поддерживается")
+			http.Error(w, `{"error":"метод не поддерживается"}`, http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{})
+	}
+}
+
+// Validate проверяет название пункта чек-листа
+func (i *ChecklistItem) Validate() error {
+	i.Title = strings.TrimSpace(i.Title)
+	if i.Title == "" {
+		logger.LogMessage("[ERROR] Не указано название пункта чек-листа")
+		return errors.New("не указано название пункта чек-листа")
+	}
+	return nil
+}
+
+// checklistPending проверяет, остались ли в чек-листе невыполненные пункты
+func checklistPending(items []ChecklistItem) bool {
+	for _, item := range items {
+		if !item.Done {
+			return true
+		}
+	}
+	return false
+}
+
+func updateChecklistItem(store TaskStore, id string, item ChecklistItem) error {
+	if item.ID == "" {
+		logger.LogMessage("[ERROR] Не указан идентификатор пункта чек-листа")
+		return errors.New("не указан идентификатор пункта чек-листа")
+	}
+	return store.UpdateChecklistItem(id, item)
+}
+
+// nonNilItems возвращает пустой срез вместо nil, чтобы в JSON был пустой массив
+func nonNilItems(items []ChecklistItem) []ChecklistItem {
+	if items == nil {
+		return []ChecklistItem{}
+	}
+	return items
+}
@@ -0,0 +1,71 @@
+package task
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go_final_project/internal/logger"
+)
+
+// DepsHandler управляет зависимостями задачи id: GET возвращает задачи,
+// которые её блокируют, и задачи, которые блокирует она; POST добавляет
+// блокирующую задачу blocked_by, DELETE убирает её.
+func DepsHandler(store TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			logger.LogMessage("[ERROR] Не указан идентификатор задачи")
+			http.Error(w, `{"error":"не указан идентификатор задачи"}`, http.StatusBadRequest)
+			return
+		}
+
+		task, err := store.GetTask(id)
+		if err != nil {
+			logger.LogMessage("[ERROR] Задача не найдена")
+			http.Error(w, `{"error":"задача не найдена"}`, http.StatusNotFound)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"blocked_by": nonNil(task.BlockedBy),
+				"blocking":   nonNil(task.Blocking),
+			})
+			return
+		}
+
+		blocker := r.URL.Query().Get("blocked_by")
+		if blocker == "" {
+			logger.LogMessage("[ERROR] Не указана блокирующая задача")
+			http.Error(w, `{"error":"не указана блокирующая задача"}`, http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPost:
+			blockerTask, err := store.GetTask(blocker)
+			if err != nil {
+				logger.LogMessage("[ERROR] Блокирующая задача не найдена")
+				http.Error(w, `{"error":"блокирующая задача не найдена"}`, http.StatusNotFound)
+				return
+			}
+			if err := store.AddDependency(task.ID, blockerTask.ID); err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+				return
+			}
+		case http.MethodDelete:
+			if err := store.DeleteDependency(task.ID, blocker); err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+				return
+			}
+		default:
+			logger.LogMessage("[ERROR] Метод не поддерживается")
+			http.Error(w, `{"error":"метод не поддерживается"}`, http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{})
+	}
+}
@@ -4,17 +4,14 @@ import (
 	"encoding/json"
 	"errors"
 	"go_final_project/internal/logger"
-	"log"
 	"net/http"
 	"time"
 
 	"go_final_project/internal"
 	"go_final_project/internal/scheduler"
-
-	"github.com/jmoiron/sqlx"
 )
 
-func DoneTaskHandler(db *sqlx.DB) http.HandlerFunc {
+func DoneTaskHandler(store TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := r.URL.Query().Get("id")
 
@@ -30,7 +27,7 @@ func DoneTaskHandler(db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
-		task, err := getTaskByID(db, id)
+		task, err := store.GetTask(id)
 		if err != nil {
 			logger.LogMessage("[ERROR] Задача не найдена")
 			http.Error(w, `{"error":"задача не найдена"}`, http.StatusNotFound)
@@ -39,31 +36,46 @@ func DoneTaskHandler(db *sqlx.DB) http.HandlerFunc {
 
 		switch r.Method {
 		case http.MethodPost:
-			if task.Repeat == "" {
-				err = deleteTask(db, id)
-				if err != nil {
-					logger.LogMessage("[ERROR] Ошибка удаления задачи")
-					http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
-					return
-				}
-			} else {
-				today, _ := time.Parse(internal.DateLayout, task.Date)
-				nextDate, err := scheduler.NextDate(today, task.Date, task.Repeat)
-				if err != nil {
-					logger.LogMessage("[ERROR] Ошибка расчёта следующей даты")
-					http.Error(w, `{"error":"ошибка расчёта следующей даты"}`, http.StatusInternalServerError)
-					return
-				}
-				err = updateTaskDate(db, id, nextDate)
-				if err != nil {
-					logger.LogMessage("[ERROR] Ошибка обновления даты задачи")
-					http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
-					return
-				}
+			policy := r.URL.Query().Get("policy")
+			if policy == "" {
+				policy = donePolicy
+			}
+			if policy != DonePolicyRefuse && policy != DonePolicyCascade {
+				logger.LogMessage("[ERROR] Неизвестная политика выполнения задачи: " + policy)
+				http.Error(w, `{"error":"неизвестная политика выполнения задачи"}`, http.StatusBadRequest)
+				return
+			}
+			if policy == DonePolicyRefuse && checklistPending(task.Checklist) {
+				logger.LogMessage("[ERROR] В задаче есть невыполненные пункты чек-листа")
+				http.Error(w, `{"error":"в задаче есть невыполненные пункты чек-листа"}`, http.StatusConflict)
+				return
+			}
+
+			note, err := readCompletionNote(r)
+			if err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+				return
+			}
+			completion, err := newCompletion(task, note)
+			if err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+				return
+			}
+			next, err := nextOccurrence(task)
+			if err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+				return
+			}
+			// Однократная задача и задача с исчерпанной серией попадают в корзину,
+			// откуда их можно восстановить, повторяющаяся — переносится на next
+			err = store.CompleteOccurrence(id, completion, next, policy == DonePolicyCascade && checklistPending(task.Checklist))
+			if err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+				return
 			}
 
 		case http.MethodDelete:
-			err = deleteTask(db, id)
+			err = store.TrashTask(id, false)
 			if err != nil {
 				logger.LogMessage("[ERROR] Ошибка удаления задачи")
 				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
@@ -81,22 +93,53 @@ func DoneTaskHandler(db *sqlx.DB) http.HandlerFunc {
 	}
 }
 
-func deleteTask(db *sqlx.DB, id string) error {
-	_, err := db.Exec("DELETE FROM scheduler WHERE id=?", id)
+// nextOccurrence возвращает следующее повторение задачи после выполнения;
+// nil — задача однократная или серия её повторений исчерпана
+func nextOccurrence(task *Task) (*Occurrence, error) {
+	if task.Repeat == "" {
+		return nil, nil
+	}
+	rule, err := task.Rule()
 	if err != nil {
-		logger.LogMessage("[ERROR] Ошибка удаления задачи с ID " + id + ": " + err.Error())
-		log.Printf("Ошибка удаления задачи с ID %s: %v", id, err)
-		return errors.New("ошибка удаления задачи")
+		logger.LogMessage("[ERROR] Некорректное правило повторения")
+		return nil, err
 	}
-	return nil
+	// При повторении от даты выполнения отсчёт идёт от сегодняшнего дня,
+	// а для правил h и min — следующий слот после текущего момента
+	after := rule.Start
+	if rule.FromDone || rule.SubDaily() {
+		loc, err := task.Location()
+		if err != nil {
+			logger.LogMessage("[ERROR] " + err.Error())
+			return nil, err
+		}
+		after = scheduler.Now(loc)
+	}
+	next, scheduled, err := rule.NextScheduled(after)
+	if errors.Is(err, scheduler.ErrNoMoreOccurrences) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.LogMessage("[ERROR] Ошибка расчёта следующей даты")
+		return nil, errors.New("ошибка расчёта следующей даты")
+	}
+	clock := task.Time
+	if rule.SubDaily() {
+		clock = next.Format(internal.TimeLayout)
+	}
+	return &Occurrence{
+		Date:   next.Format(internal.DateLayout),
+		Time:   clock,
+		Repeat: scheduler.ConsumeOccurrence(task.Repeat),
+		Anchor: anchorDate(next, scheduled),
+	}, nil
 }
 
-func updateTaskDate(db *sqlx.DB, id, date string) error {
-	_, err := db.Exec("UPDATE scheduler SET date=? WHERE id=?", date, id)
-	if err != nil {
-		logger.LogMessage("[ERROR] Ошибка обновления даты задачи с ID " + id + ": " + err.Error())
-		log.Printf("Ошибка обновления даты задачи с ID %s: %v", id, err)
-		return errors.New("ошибка обновления даты задачи")
+// anchorDate возвращает дату повторения по графику, если roll перенёс
+// повторение next на другой день, иначе пустую строку
+func anchorDate(next, scheduled time.Time) string {
+	if next.Format(internal.DateLayout) == scheduled.Format(internal.DateLayout) {
+		return ""
 	}
-	return nil
+	return scheduled.Format(internal.DateLayout)
 }
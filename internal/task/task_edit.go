@@ -3,19 +3,16 @@ package task
 import (
 	"encoding/json"
 	"errors"
-
+	"io"
 	"log"
 	"net/http"
 	"strconv"
-	"time"
 
 	"go_final_project/internal/logger"
 	"go_final_project/internal/scheduler"
-
-	"github.com/jmoiron/sqlx"
 )
 
-func GetTaskHandler(db *sqlx.DB) http.HandlerFunc {
+func GetTaskHandler(store TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := r.URL.Query().Get("id")
 		if id == "" {
@@ -24,19 +21,30 @@ func GetTaskHandler(db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
-		task, err := getTaskByID(db, id)
+		task, err := store.GetTask(id)
 		if err != nil {
 			logger.LogMessage("[ERROR] " + err.Error())
 			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusNotFound)
 			return
 		}
 
+		if task.Repeat != "" {
+			if rule, err := task.Rule(); err == nil {
+				task.RepeatText, err = rule.Describe(scheduler.DescribeLang(r))
+				if err != nil {
+					logger.LogMessage("[ERROR] Ошибка описания правила повторения: " + err.Error())
+				}
+			}
+		}
+
+		task.setDue()
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(task)
 	}
 }
 
-func EditTaskHandler(db *sqlx.DB) http.HandlerFunc {
+func EditTaskHandler(store TaskStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPut {
 			logger.LogMessage("[ERROR] Метод не поддерживается")
@@ -44,10 +52,19 @@ func EditTaskHandler(db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
+		// Поля задачи разбираются ещё и в словарь, чтобы отличить отсутствующее поле от пустого
 		var task Task
-		if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
-			logger.LogMessage("[ERROR] Ошибка разбора JSON")
-			http.Error(w, `{"error":"ошибка разбора JSON"}`, http.StatusBadRequest)
+		var fields map[string]json.RawMessage
+		body, err := io.ReadAll(r.Body)
+		if err == nil {
+			err = json.Unmarshal(body, &task)
+		}
+		if err == nil {
+			err = json.Unmarshal(body, &fields)
+		}
+		if err != nil {
+			logger.LogMessage("[ERROR] Ошибка разбора JSON: " + err.Error())
+			http.Error(w, `{"error":"`+taskJSONError(err)+`"}`, http.StatusBadRequest)
 			return
 		}
 
@@ -63,28 +80,27 @@ func EditTaskHandler(db *sqlx.DB) http.HandlerFunc {
 			return
 		}
 
-		if _, err := getTaskByID(db, task.ID); err != nil {
+		current, err := store.GetTask(task.ID)
+		if err != nil {
 			logger.LogMessage("[ERROR] Задача не найдена")
 			http.Error(w, `{"error":"задача не найдена"}`, http.StatusNotFound)
 			return
 		}
 
-		if err := task.Validate(); err != nil {
-			logger.LogMessage("[ERROR] " + err.Error())
+		keepMissingFields(&task, current, fields)
+
+		if err := resolveProject(store, &task, current.ProjectID); err != nil {
 			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
 			return
 		}
 
-		if task.Repeat != "" {
-			today := time.Now()
-			if _, err := scheduler.NextDate(today, task.Date, task.Repeat); err != nil {
-				logger.LogMessage("[ERROR] Некорректное правило повторения")
-				http.Error(w, `{"error":"некорректное правило повторения"}`, http.StatusBadRequest)
-				return
-			}
+		if err := task.Validate(); err != nil {
+			logger.LogMessage("[ERROR] " + err.Error())
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+			return
 		}
 
-		if err := updateTask(db, &task); err != nil {
+		if err := store.UpdateTask(&task); err != nil {
 			logger.LogMessage("[ERROR] Ошибка обновления задачи")
 			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
 			return
@@ -96,37 +112,31 @@ func EditTaskHandler(db *sqlx.DB) http.HandlerFunc {
 	}
 }
 
-func getTaskByID(db *sqlx.DB, id string) (*Task, error) {
-	var task Task
-	var numericID int64
-	var err error
-
-	numericID, err = strconv.ParseInt(id, 10, 64)
-	if err != nil {
-		logger.LogMessage("[ERROR] Ошибка преобразования ID задачи: " + err.Error())
-		log.Printf("Ошибка преобразования ID задачи (%s): %v", id, err)
-		return nil, errors.New("Некорректный идентификатор задачи")
+// keepMissingFields оставляет прежними поля задачи, которых нет в запросе на изменение,
+// как это делается для меток, чек-листа и проекта. Пустое значение поле очищает.
+func keepMissingFields(task, current *Task, fields map[string]json.RawMessage) {
+	if _, ok := fields["priority"]; !ok {
+		task.Priority = current.Priority
 	}
-
-	query := "SELECT id, date, title, comment, repeat FROM scheduler WHERE id = ?"
-	err = db.Get(&task, query, numericID)
-	if err != nil {
-		logger.LogMessage("[ERROR] Задача не найдена")
-		log.Printf("Задача с ID %d не найдена: %v", numericID, err)
-		return nil, errors.New("Задача не найдена")
+	if _, ok := fields["time"]; !ok {
+		task.Time = current.Time
+	}
+	if _, ok := fields["timezone"]; !ok {
+		task.Timezone = current.Timezone
+	}
+	// Дата по графику не передаётся клиенту и сохраняется, пока не изменились дата и правило
+	if task.Date == current.Date && task.Repeat == current.Repeat {
+		task.AnchorDate = current.AnchorDate
 	}
-
-	task.ID = strconv.FormatInt(numericID, 10)
-	return &task, nil
 }
 
-func updateTask(db *sqlx.DB, task *Task) error {
-	query := `UPDATE scheduler SET date=?, title=?, comment=?, repeat=? WHERE id=?`
-	_, err := db.Exec(query, task.Date, task.Title, task.Comment, task.Repeat, task.ID)
+// parseTaskID проверяет, что идентификатор задачи — число
+func parseTaskID(id string) (int64, error) {
+	numericID, err := strconv.ParseInt(id, 10, 64)
 	if err != nil {
-		logger.LogMessage("[ERROR] Ошибка обновления задачи: " + err.Error())
-		log.Printf("Ошибка обновления задачи с ID %s: %v", task.ID, err)
-		return errors.New("ошибка обновления задачи")
+		logger.LogMessage("[ERROR] Ошибка преобразования ID задачи: " + err.Error())
+		log.Printf("Ошибка преобразования ID задачи (%s): %v", id, err)
+		return 0, errors.New("Некорректный идентификатор задачи")
 	}
-	return nil
+	return numericID, nil
 }
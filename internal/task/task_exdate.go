@@ -0,0 +1,111 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"go_final_project/internal"
+	"go_final_project/internal/logger"
+	"go_final_project/internal/scheduler"
+)
+
+// ExDateHandler управляет датами-исключениями повторяющейся задачи:
+// GET возвращает список, POST добавляет дату, DELETE удаляет её.
+func ExDateHandler(store TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			logger.LogMessage("[ERROR] Не указан идентификатор задачи")
+			http.Error(w, `{"error":"не указан идентификатор задачи"}`, http.StatusBadRequest)
+			return
+		}
+
+		task, err := store.GetTask(id)
+		if err != nil {
+			logger.LogMessage("[ERROR] Задача не найдена")
+			http.Error(w, `{"error":"задача не найдена"}`, http.StatusNotFound)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"exdates": nonNil(task.ExDates)})
+			return
+		}
+
+		date := r.URL.Query().Get("date")
+		if _, err := time.Parse(internal.DateLayout, date); err != nil {
+			logger.LogMessage("[ERROR] Дата исключения указана в неверном формате YYYYMMDD")
+			http.Error(w, `{"error":"дата исключения указана в неверном формате YYYYMMDD"}`, http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPost:
+			if err := addExDate(store, task, date); err != nil {
+				logger.LogMessage("[ERROR] " + err.Error())
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+				return
+			}
+		case http.MethodDelete:
+			if err := store.DeleteExDate(id, date); err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+				return
+			}
+		default:
+			logger.LogMessage("[ERROR] Метод не поддерживается")
+			http.Error(w, `{"error":"метод не поддерживается"}`, http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{})
+	}
+}
+
+// addExDate добавляет дату-исключение. Если пропускается текущая дата задачи,
+// задача переносится на следующую дату по графику.
+func addExDate(store TaskStore, task *Task, date string) error {
+	if task.Repeat == "" {
+		return errors.New("исключения можно добавлять только для повторяющихся задач")
+	}
+
+	task.ExDates = append(task.ExDates, date)
+	rule, err := task.Rule()
+	if err != nil {
+		return err
+	}
+
+	var next *Occurrence
+	if date == task.Date {
+		nextDate, scheduled, err := rule.NextScheduled(rule.Start)
+		if errors.Is(err, scheduler.ErrNoMoreOccurrences) {
+			return errors.New("нельзя пропустить последнее повторение задачи")
+		}
+		if err != nil {
+			return err
+		}
+		// Пропуск не расходует повторение серии, поэтому правило не меняется
+		next = &Occurrence{
+			Date:   nextDate.Format(internal.DateLayout),
+			Time:   task.Time,
+			Repeat: task.Repeat,
+			Anchor: anchorDate(nextDate, scheduled),
+		}
+		if rule.SubDaily() {
+			next.Time = nextDate.Format(internal.TimeLayout)
+		}
+	}
+
+	return store.SkipOccurrence(task.ID, date, next)
+}
+
+// nonNil возвращает пустой срез вместо nil, чтобы в JSON был пустой массив
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,86 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"go_final_project/internal/logger"
+	"go_final_project/internal/scheduler"
+)
+
+// Completion — запись о выполнении задачи: на какую дату задача была
+// запланирована, когда её фактически выполнили и необязательная заметка
+type Completion struct {
+	ID            string `db:"id" json:"id"`
+	ScheduledDate string `db:"scheduled_date" json:"scheduled_date"`
+	ScheduledTime string `db:"scheduled_time" json:"scheduled_time,omitempty"`
+	CompletedAt   string `db:"completed_at" json:"completed_at"`
+	Note          string `db:"note" json:"note,omitempty"`
+}
+
+// HistoryHandler возвращает историю выполнений задачи id, начиная с последнего.
+// История сохраняется и после того, как задача выполнена окончательно и попала в корзину.
+func HistoryHandler(store TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			logger.LogMessage("[ERROR] Метод не поддерживается")
+			http.Error(w, `{"error":"метод не поддерживается"}`, http.StatusMethodNotAllowed)
+			return
+		}
+
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			logger.LogMessage("[ERROR] Не указан идентификатор задачи")
+			http.Error(w, `{"error":"не указан идентификатор задачи"}`, http.StatusBadRequest)
+			return
+		}
+
+		// История есть у активной задачи и у задачи в корзине; после окончательного
+		// удаления задача считается ненайденной
+		if _, err := store.GetTask(id); err != nil {
+			if _, err := store.GetTrashedTask(id); err != nil {
+				logger.LogMessage("[ERROR] Задача не найдена")
+				http.Error(w, `{"error":"задача не найдена"}`, http.StatusNotFound)
+				return
+			}
+		}
+
+		history, err := store.ListCompletions(id)
+		if err != nil {
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"history": history})
+	}
+}
+
+// readCompletionNote читает необязательную заметку {"note": "..."} из тела запроса
+func readCompletionNote(r *http.Request) (string, error) {
+	var body struct {
+		Note string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		logger.LogMessage("[ERROR] Ошибка разбора JSON")
+		return "", errors.New("ошибка разбора JSON")
+	}
+	return body.Note, nil
+}
+
+// newCompletion возвращает запись о выполнении задачи в её текущую запланированную дату
+func newCompletion(task *Task, note string) (Completion, error) {
+	loc, err := task.Location()
+	if err != nil {
+		return Completion{}, err
+	}
+	return Completion{
+		ScheduledDate: task.Date,
+		ScheduledTime: task.Time,
+		CompletedAt:   scheduler.Now(loc).Format(time.RFC3339),
+		Note:          note,
+	}, nil
+}
@@ -0,0 +1,138 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go_final_project/internal/logger"
+)
+
+// projectColor — допустимый цвет проекта в формате #RRGGBB
+var projectColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Project описывает проект — именованный список задач
+type Project struct {
+	ID       string `db:"id" json:"id"`
+	Name     string `db:"name" json:"name"`
+	Color    string `db:"color" json:"color,omitempty"`
+	Archived bool   `db:"archived" json:"archived"`
+	Tasks    int    `db:"tasks" json:"tasks,string"`
+}
+
+// ProjectsHandler управляет проектами: GET возвращает список (архивные —
+// при archived=1), POST создаёт проект, PUT изменяет название, цвет и признак
+// архива, DELETE удаляет проект, оставляя его задачи без проекта.
+func ProjectsHandler(store TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			projects, err := store.ListProjects(r.URL.Query().Get("archived") == "1")
+			if err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"projects": projects})
+			return
+
+		case http.MethodPost, http.MethodPut:
+			var project Project
+			if err := json.NewDecoder(r.Body).Decode(&project); err != nil {
+				logger.LogMessage("[ERROR] Ошибка разбора JSON")
+				http.Error(w, `{"error":"ошибка разбора JSON"}`, http.StatusBadRequest)
+				return
+			}
+			if err := project.Validate(); err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+				return
+			}
+
+			if r.Method == http.MethodPost {
+				id, err := store.CreateProject(&project)
+				if err != nil {
+					http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]interface{}{"id": id})
+				return
+			}
+
+			if project.ID == "" {
+				logger.LogMessage("[ERROR] Не указан идентификатор проекта")
+				http.Error(w, `{"error":"не указан идентификатор проекта"}`, http.StatusBadRequest)
+				return
+			}
+			if err := store.UpdateProject(&project); err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+				return
+			}
+
+		case http.MethodDelete:
+			id := r.URL.Query().Get("id")
+			if id == "" {
+				logger.LogMessage("[ERROR] Не указан идентификатор проекта")
+				http.Error(w, `{"error":"не указан идентификатор проекта"}`, http.StatusBadRequest)
+				return
+			}
+			if err := store.DeleteProject(id); err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+				return
+			}
+
+		default:
+			logger.LogMessage("[ERROR] Метод не поддерживается")
+			http.Error(w, `{"error":"метод не поддерживается"}`, http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{})
+	}
+}
+
+// Validate проверяет название и цвет проекта
+func (p *Project) Validate() error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		logger.LogMessage("[ERROR] Не указано название проекта")
+		return errors.New("не указано название проекта")
+	}
+	if p.Color != "" && !projectColor.MatchString(p.Color) {
+		logger.LogMessage("[ERROR] Цвет проекта указан в неверном формате #RRGGBB")
+		return errors.New("цвет проекта указан в неверном формате #RRGGBB")
+	}
+	return nil
+}
+
+// checkProject проверяет, что задачу можно поместить в проект: проект
+// существует и не находится в архиве
+func checkProject(store TaskStore, id int64) error {
+	project, err := store.GetProject(id)
+	if err != nil {
+		return err
+	}
+	if project.Archived {
+		logger.LogMessage("[ERROR] Проект в архиве: " + strconv.FormatInt(id, 10))
+		return errors.New("проект в архиве")
+	}
+	return nil
+}
+
+// resolveProject проверяет проект задачи при сохранении. Отсутствующий
+// project_id оставляет текущий проект current, "0" убирает задачу из проекта.
+func resolveProject(store TaskStore, t *Task, current *int64) error {
+	switch {
+	case t.ProjectID == nil:
+		t.ProjectID = current
+	case *t.ProjectID == 0:
+		t.ProjectID = nil
+	case current == nil || *current != *t.ProjectID:
+		return checkProject(store, *t.ProjectID)
+	}
+	return nil
+}
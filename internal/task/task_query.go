@@ -0,0 +1,496 @@
+package task
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go_final_project/internal"
+)
+
+// queryKind — вид узла поискового запроса
+type queryKind int
+
+const (
+	queryAnd queryKind = iota
+	queryOr
+	queryNot
+	// queryText — слово или фраза в заголовке и (или) комментарии
+	queryText
+	// queryDate — дата задачи в диапазоне
+	queryDate
+	// queryTag — хотя бы одна из меток
+	queryTag
+	// queryRepeat — наличие правила повторения
+	queryRepeat
+)
+
+// queryNode — узел дерева поискового запроса
+type queryNode struct {
+	kind     queryKind
+	children []*queryNode
+	// term — терм узла queryText
+	term searchTerm
+	// from и to — границы диапазона queryDate в формате YYYYMMDD включительно,
+	// пустая граница — без ограничения
+	from, to string
+	// tags — метки узла queryTag
+	tags []string
+	// repeat — задача должна (true) или не должна повторяться
+	repeat bool
+}
+
+// Query — разобранный поисковый запрос к списку задач. Запрос состоит из условий:
+//
+//	купить, "купить молоко", куп*   — слово, фраза или начало слова в заголовке или комментарии
+//	title:купить, comment:"с собой" — то же только в заголовке или только в комментарии
+//	01.03.2025                      — задачи на дату
+//	date:01.03.2025..31.03.2025     — задачи в диапазоне дат, границу можно опустить
+//	due:today, due:tomorrow         — задачи на сегодня или на завтра
+//	due:overdue, due:+7d            — просроченные задачи и задачи на ближайшие N дней
+//	repeat:yes, repeat:no           — повторяющиеся или однократные задачи
+//	tag:work,home                   — задачи хотя бы с одной из меток
+//
+// Условия подряд должны выполняться одновременно (AND можно не писать), OR — любое
+// из условий, NOT или минус перед условием — отрицание; порядок меняется скобками.
+type Query struct {
+	root *queryNode
+	// terms — слова и фразы вне отрицаний: по ним упорядочиваются
+	// найденные задачи и строятся фрагменты текста
+	terms []searchTerm
+}
+
+// ParseQuery разбирает поисковый запрос; относительные даты due: отсчитываются
+// от дня today. Пустой запрос оставляет все задачи.
+func ParseQuery(search string, today time.Time) (*Query, error) {
+	tokens, err := lexQuery(search)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens, today: today}
+	root, _, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, queryError("лишняя закрывающая скобка")
+	}
+
+	q := &Query{root: root}
+	q.collectTerms(root, false)
+	return q, nil
+}
+
+// empty проверяет, что запрос не ограничивает список задач
+func (q *Query) empty() bool {
+	return q == nil || q.root == nil
+}
+
+// rankTerms возвращает термы, по которым упорядочиваются найденные задачи;
+// пустой список — задачи идут в обычном порядке
+func (q *Query) rankTerms() []searchTerm {
+	if q.empty() {
+		return nil
+	}
+	return q.terms
+}
+
+func (q *Query) collectTerms(n *queryNode, negated bool) {
+	if n == nil {
+		return
+	}
+	switch n.kind {
+	case queryText:
+		if !negated {
+			q.terms = append(q.terms, n.term)
+		}
+	case queryNot:
+		negated = !negated
+	}
+	for _, child := range n.children {
+		q.collectTerms(child, negated)
+	}
+}
+
+func queryError(message string) error {
+	return errors.New("некорректный поисковый запрос: " + message)
+}
+
+// tokenKind — вид лексемы поискового запроса
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenPhrase
+	tokenField
+	tokenLParen
+	tokenRParen
+	tokenAnd
+	tokenOr
+	tokenNot
+)
+
+// queryToken — лексема поискового запроса
+type queryToken struct {
+	kind tokenKind
+	// text — слово, текст фразы или значение поля
+	text string
+	// field — имя поля лексемы tokenField в нижнем регистре
+	field string
+	// quoted — значение поля записано в кавычках
+	quoted bool
+	// prefix — после фразы в кавычках стоит звёздочка или это последнее слово запроса
+	prefix bool
+}
+
+// queryFieldRe выделяет имя поля и значение в условии вида field:value
+var queryFieldRe = regexp.MustCompile(`^([A-Za-z]+):(.*)$`)
+
+// queryFields — поля, которые разбираются как условия. Слово с другим именем поля
+// или без значения ("Re:", "http://...") ищется как обычный текст.
+var queryFields = map[string]bool{
+	"title":   true,
+	"comment": true,
+	"tag":     true,
+	"date":    true,
+	"due":     true,
+	"repeat":  true,
+}
+
+// lexQuery делит поисковый запрос на лексемы
+func lexQuery(search string) ([]queryToken, error) {
+	var tokens []queryToken
+	for {
+		search = strings.TrimLeftFunc(search, unicode.IsSpace)
+		if search == "" {
+			markLastWord(tokens)
+			return tokens, nil
+		}
+
+		switch {
+		case search[0] == '(':
+			tokens = append(tokens, queryToken{kind: tokenLParen})
+			search = search[1:]
+			continue
+		case search[0] == ')':
+			tokens = append(tokens, queryToken{kind: tokenRParen})
+			search = search[1:]
+			continue
+		case search[0] == '-' && len(search) > 1 && !unicode.IsSpace(rune(search[1])):
+			tokens = append(tokens, queryToken{kind: tokenNot})
+			search = search[1:]
+			continue
+		}
+
+		var token queryToken
+		var err error
+		if search[0] == '"' {
+			token.kind = tokenPhrase
+			if token.text, search, err = lexPhrase(search); err != nil {
+				return nil, err
+			}
+		} else {
+			end := strings.IndexFunc(search, func(r rune) bool {
+				return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+			})
+			if end < 0 {
+				end = len(search)
+			}
+			word := search[:end]
+			search = search[end:]
+
+			switch word {
+			case "AND":
+				token.kind = tokenAnd
+			case "OR":
+				token.kind = tokenOr
+			case "NOT":
+				token.kind = tokenNot
+			default:
+				token = queryToken{kind: tokenWord, text: word}
+				match := queryFieldRe.FindStringSubmatch(word)
+				if match != nil && queryFields[strings.ToLower(match[1])] &&
+					(match[2] != "" || strings.HasPrefix(search, `"`)) {
+					token = queryToken{kind: tokenField, field: strings.ToLower(match[1]), text: match[2]}
+					if token.text == "" {
+						token.quoted = true
+						if token.text, search, err = lexPhrase(search); err != nil {
+							return nil, err
+						}
+					}
+				}
+			}
+		}
+
+		if token.kind == tokenPhrase || token.quoted {
+			if rest, ok := strings.CutPrefix(search, "*"); ok {
+				token.prefix, search = true, rest
+			}
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+// markLastWord отмечает последнее слово запроса без кавычек и поля (до закрывающих
+// скобок) как начало слова: запрос ищет задачи, пока слово ещё набирается
+func markLastWord(tokens []queryToken) {
+	i := len(tokens) - 1
+	for i >= 0 && tokens[i].kind == tokenRParen {
+		i--
+	}
+	if i >= 0 && tokens[i].kind == tokenWord {
+		tokens[i].prefix = true
+	}
+}
+
+// lexPhrase выделяет фразу в кавычках в начале строки и возвращает её текст и остаток строки
+func lexPhrase(search string) (string, string, error) {
+	phrase, rest, found := strings.Cut(search[1:], `"`)
+	if !found {
+		return "", "", queryError("незакрытая кавычка")
+	}
+	return phrase, rest, nil
+}
+
+// queryParser разбирает лексемы методом рекурсивного спуска:
+//
+//	or    = and { "OR" and }
+//	and   = unary { ["AND"] unary }
+//	unary = ("NOT" | "-") unary | "(" or ")" | слово | фраза | поле
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+	today  time.Time
+}
+
+func (p *queryParser) peek() queryToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return queryToken{kind: tokenEOF}
+}
+
+func (p *queryParser) next() queryToken {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+// endOfOperand проверяет, что дальше нет условия для оператора
+func (p *queryParser) endOfOperand() bool {
+	switch p.peek().kind {
+	case tokenEOF, tokenRParen, tokenAnd, tokenOr:
+		return true
+	}
+	return false
+}
+
+// parseOr разбирает условия, соединённые OR, и возвращает их дерево и число условий
+func (p *queryParser) parseOr() (*queryNode, int, error) {
+	node, items, err := p.parseAnd()
+	if err != nil {
+		return nil, 0, err
+	}
+	for p.peek().kind == tokenOr {
+		if items == 0 {
+			return nil, 0, queryError("нет условия перед OR")
+		}
+		p.next()
+		right, n, err := p.parseAnd()
+		if err != nil {
+			return nil, 0, err
+		}
+		if n == 0 {
+			return nil, 0, queryError("нет условия после OR")
+		}
+		node = combineQuery(queryOr, node, right)
+		items += n
+	}
+	return node, items, nil
+}
+
+// parseAnd разбирает условия, которые должны выполняться одновременно
+func (p *queryParser) parseAnd() (*queryNode, int, error) {
+	var children []*queryNode
+	items := 0
+	for {
+		switch p.peek().kind {
+		case tokenEOF, tokenRParen, tokenOr:
+			return combineQuery(queryAnd, children...), items, nil
+		case tokenAnd:
+			if items == 0 {
+				return nil, 0, queryError("нет условия перед AND")
+			}
+			p.next()
+			if p.endOfOperand() {
+				return nil, 0, queryError("нет условия после AND")
+			}
+			continue
+		}
+
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, 0, err
+		}
+		children = append(children, node)
+		items++
+	}
+}
+
+func (p *queryParser) parseUnary() (*queryNode, error) {
+	token := p.next()
+	switch token.kind {
+	case tokenNot:
+		if p.endOfOperand() {
+			return nil, queryError("нет условия после NOT")
+		}
+		node, err := p.parseUnary()
+		if err != nil || node == nil {
+			return nil, err
+		}
+		return &queryNode{kind: queryNot, children: []*queryNode{node}}, nil
+
+	case tokenLParen:
+		node, items, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokenRParen {
+			return nil, queryError("незакрытая скобка")
+		}
+		if items == 0 {
+			return nil, queryError("пустые скобки")
+		}
+		return node, nil
+
+	case tokenPhrase:
+		return textQuery("", token.text, token.prefix), nil
+
+	case tokenField:
+		return p.parseField(token)
+	}
+
+	// Дата в формате DD.MM.YYYY без поля, как и раньше, выбирает задачи на эту дату
+	if isValidDateFormat(token.text) {
+		date := convertToDBDateFormat(token.text)
+		return &queryNode{kind: queryDate, from: date, to: date}, nil
+	}
+	return textQuery("", token.text, token.prefix || strings.HasSuffix(token.text, "*")), nil
+}
+
+// parseField разбирает условие вида field:value
+func (p *queryParser) parseField(token queryToken) (*queryNode, error) {
+	if token.text == "" {
+		return nil, queryError("не указано значение поля " + token.field + ":")
+	}
+
+	switch token.field {
+	case "title", "comment":
+		prefix := token.prefix || !token.quoted && strings.HasSuffix(token.text, "*")
+		return textQuery(token.field, token.text, prefix), nil
+
+	case "tag":
+		tags, err := normalizeTags(strings.Split(token.text, ","))
+		if err != nil {
+			return nil, err
+		}
+		return &queryNode{kind: queryTag, tags: tags}, nil
+
+	case "date":
+		return parseDateRange(token.text)
+
+	case "due":
+		return p.parseDue(token.text)
+
+	case "repeat":
+		switch token.text {
+		case "yes", "no":
+			return &queryNode{kind: queryRepeat, repeat: token.text == "yes"}, nil
+		}
+		return nil, queryError("значение repeat: должно быть yes или no")
+	}
+	return nil, queryError("неизвестное поле " + token.field + ":")
+}
+
+// parseDateRange разбирает дату DD.MM.YYYY или диапазон дат DD.MM.YYYY..DD.MM.YYYY
+func parseDateRange(value string) (*queryNode, error) {
+	from, to, isRange := strings.Cut(value, "..")
+	if !isRange {
+		to = from
+	}
+	if from == "" && to == "" {
+		return nil, queryError("не указан диапазон дат")
+	}
+
+	node := &queryNode{kind: queryDate}
+	for _, bound := range []struct {
+		value string
+		date  *string
+	}{{from, &node.from}, {to, &node.to}} {
+		if bound.value == "" {
+			continue
+		}
+		if !isValidDateFormat(bound.value) {
+			return nil, queryError("дата должна быть в формате DD.MM.YYYY")
+		}
+		*bound.date = convertToDBDateFormat(bound.value)
+	}
+	if node.from != "" && node.to != "" && node.from > node.to {
+		return nil, queryError("начало диапазона дат позже его конца")
+	}
+	return node, nil
+}
+
+// dueDaysRe разбирает значение due:+Nd
+var dueDaysRe = regexp.MustCompile(`^\+(\d{1,4})d$`)
+
+// parseDue разбирает относительный срок задачи
+func (p *queryParser) parseDue(value string) (*queryNode, error) {
+	day := func(days int) string {
+		return p.today.AddDate(0, 0, days).Format(internal.DateLayout)
+	}
+
+	switch value {
+	case "today":
+		return &queryNode{kind: queryDate, from: day(0), to: day(0)}, nil
+	case "tomorrow":
+		return &queryNode{kind: queryDate, from: day(1), to: day(1)}, nil
+	case "overdue":
+		return &queryNode{kind: queryDate, to: day(-1)}, nil
+	}
+	if match := dueDaysRe.FindStringSubmatch(value); match != nil {
+		days, _ := strconv.Atoi(match[1])
+		return &queryNode{kind: queryDate, from: day(0), to: day(days)}, nil
+	}
+	return nil, queryError("значение due: должно быть today, tomorrow, overdue или +Nd")
+}
+
+// textQuery возвращает условие поиска текста; текст без букв и цифр не ограничивает выборку
+func textQuery(field, text string, prefix bool) *queryNode {
+	words := searchWords(text)
+	if len(words) == 0 {
+		return nil
+	}
+	return &queryNode{kind: queryText, term: searchTerm{field: field, words: words, prefix: prefix}}
+}
+
+// combineQuery соединяет условия оператором kind, пропуская пустые
+func combineQuery(kind queryKind, nodes ...*queryNode) *queryNode {
+	var children []*queryNode
+	for _, node := range nodes {
+		if node != nil {
+			children = append(children, node)
+		}
+	}
+	switch len(children) {
+	case 0:
+		return nil
+	case 1:
+		return children[0]
+	}
+	return &queryNode{kind: kind, children: children}
+}
@@ -0,0 +1,202 @@
+package task
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	// snippetTokens — наибольшее число слов во фрагменте с найденным текстом
+	snippetTokens = 12
+	// snippetOpen и snippetClose выделяют найденные слова во фрагменте
+	snippetOpen     = "<mark>"
+	snippetClose    = "</mark>"
+	snippetEllipsis = "…"
+	// ftsMarkOpen и ftsMarkClose — коды символов, которыми FTS5 отмечает найденные слова
+	// до экранирования фрагмента; затем они заменяются на snippetOpen и snippetClose
+	ftsMarkOpen  = 2
+	ftsMarkClose = 3
+	// titleWeight — во сколько раз совпадение в заголовке важнее совпадения в комментарии
+	titleWeight = 10
+)
+
+// searchTerm — слово или фраза поискового запроса
+type searchTerm struct {
+	// field — столбец, в котором ищется терм: title, comment или пустая строка — оба
+	field string
+	// words — слова в нижнем регистре; несколько слов ищутся подряд, как фраза
+	words []string
+	// prefix — последнее слово ищется как начало слова
+	prefix bool
+}
+
+// inColumn проверяет, ищется ли терм в столбце column
+func (term searchTerm) inColumn(column string) bool {
+	return term.field == "" || term.field == column
+}
+
+// searchWords делит текст на слова из букв и цифр и приводит их к нижнему регистру.
+// Как и unicode61 в FTS5, ё не заменяется на е: это разные буквы.
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), notWordRune)
+}
+
+func notWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// ftsTerm переводит терм в запрос MATCH для FTS5. Слова берутся в кавычки,
+// поэтому операторы FTS5 в тексте поиска не действуют.
+func ftsTerm(term searchTerm) string {
+	query := `"` + strings.Join(term.words, " ") + `"`
+	if term.prefix {
+		query += "*"
+	}
+	if term.field != "" {
+		query = term.field + " : " + query
+	}
+	return query
+}
+
+// tsTerm переводит терм в запрос to_tsquery для PostgreSQL: слова фразы
+// соединяются оператором <->
+func tsTerm(term searchTerm) string {
+	query := strings.Join(term.words, " <-> ")
+	if term.prefix {
+		query += ":*"
+	}
+	return "(" + query + ")"
+}
+
+// searchSpan — слово текста: его границы в байтах и форма в нижнем регистре
+type searchSpan struct {
+	start, end int
+	word       string
+}
+
+// searchSpans находит слова текста вместе с их положением
+func searchSpans(text string) []searchSpan {
+	var spans []searchSpan
+	start := -1
+	for i, r := range text {
+		switch {
+		case !notWordRune(r) && start < 0:
+			start = i
+		case notWordRune(r) && start >= 0:
+			spans = append(spans, searchSpan{start, i, strings.ToLower(text[start:i])})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, searchSpan{start, len(text), strings.ToLower(text[start:])})
+	}
+	return spans
+}
+
+// markMatches отмечает слова столбца column, входящие в найденные термы,
+// и возвращает число совпадений
+func markMatches(spans []searchSpan, terms []searchTerm, column string, marked []bool) int {
+	count := 0
+	for _, term := range terms {
+		if !term.inColumn(column) {
+			continue
+		}
+		for i := 0; i+len(term.words) <= len(spans); i++ {
+			if termMatches(spans[i:i+len(term.words)], term) {
+				count++
+				for j := range term.words {
+					marked[i+j] = true
+				}
+			}
+		}
+	}
+	return count
+}
+
+func termMatches(spans []searchSpan, term searchTerm) bool {
+	last := len(term.words) - 1
+	for i, word := range term.words {
+		if spans[i].word != word && !(i == last && term.prefix && strings.HasPrefix(spans[i].word, word)) {
+			return false
+		}
+	}
+	return true
+}
+
+// textMatches проверяет, что заголовок или комментарий задачи содержит терм.
+// Используется там, где нет FTS5.
+func textMatches(t *Task, term searchTerm) bool {
+	title, comment := searchSpans(t.Title), searchSpans(t.Comment)
+	terms := []searchTerm{term}
+	return markMatches(title, terms, "title", make([]bool, len(title))) > 0 ||
+		markMatches(comment, terms, "comment", make([]bool, len(comment))) > 0
+}
+
+// searchRank возвращает вес совпадений текста задачи с термами: совпадения
+// в заголовке весят больше; 0 — термы не найдены. Используется там, где нет FTS5.
+func searchRank(t *Task, terms []searchTerm) int {
+	title, comment := searchSpans(t.Title), searchSpans(t.Comment)
+	return titleWeight*markMatches(title, terms, "title", make([]bool, len(title))) +
+		markMatches(comment, terms, "comment", make([]bool, len(comment)))
+}
+
+// searchSnippet возвращает фрагмент заголовка или комментария, где найдены термы,
+// выделяя найденные слова так же, как функция snippet в FTS5
+func searchSnippet(t *Task, terms []searchTerm) string {
+	text := t.Title
+	spans := searchSpans(text)
+	marked := make([]bool, len(spans))
+	if markMatches(spans, terms, "title", marked) == 0 {
+		text = t.Comment
+		spans = searchSpans(text)
+		marked = make([]bool, len(spans))
+		markMatches(spans, terms, "comment", marked)
+	}
+	if len(spans) == 0 {
+		return html.EscapeString(text)
+	}
+
+	// Фрагмент начинается с первого найденного слова, если иначе оно в него не попадает
+	first := 0
+	for first < len(marked) && !marked[first] {
+		first++
+	}
+	start := 0
+	if first >= snippetTokens && first < len(spans) {
+		start = first
+	}
+	end := min(start+snippetTokens, len(spans))
+
+	var b strings.Builder
+	pos := spans[start].start
+	if start > 0 {
+		b.WriteString(snippetEllipsis)
+	} else {
+		pos = 0
+	}
+	for i := start; i < end; i++ {
+		b.WriteString(html.EscapeString(text[pos:spans[i].start]))
+		if marked[i] {
+			b.WriteString(snippetOpen + html.EscapeString(text[spans[i].start:spans[i].end]) + snippetClose)
+		} else {
+			b.WriteString(html.EscapeString(text[spans[i].start:spans[i].end]))
+		}
+		pos = spans[i].end
+	}
+	if end < len(spans) {
+		b.WriteString(snippetEllipsis)
+	} else {
+		b.WriteString(html.EscapeString(text[pos:]))
+	}
+	return b.String()
+}
+
+// ftsSnippet экранирует фрагмент, полученный от FTS5, и заменяет отметки
+// найденных слов тегами, чтобы текст задачи не попал в HTML как разметка
+func ftsSnippet(snippet string) string {
+	return strings.NewReplacer(
+		string(rune(ftsMarkOpen)), snippetOpen,
+		string(rune(ftsMarkClose)), snippetClose,
+	).Replace(html.EscapeString(snippet))
+}
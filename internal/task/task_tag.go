@@ -0,0 +1,131 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go_final_project/internal/logger"
+)
+
+// maxTagLength ограничивает длину названия метки в символах
+const maxTagLength = 64
+
+// Tag описывает метку задач
+type Tag struct {
+	ID    string `db:"id" json:"id"`
+	Name  string `db:"name" json:"name"`
+	Tasks int    `db:"tasks" json:"tasks,string"`
+}
+
+// TagsHandler управляет метками: GET возвращает список меток с числом задач,
+// POST создаёт метку, PUT переименовывает, DELETE удаляет метку и снимает её с задач.
+func TagsHandler(store TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			tags, err := store.ListTags()
+			if err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"tags": tags})
+			return
+
+		case http.MethodPost, http.MethodPut:
+			var tag Tag
+			if err := json.NewDecoder(r.Body).Decode(&tag); err != nil {
+				logger.LogMessage("[ERROR] Ошибка разбора JSON")
+				http.Error(w, `{"error":"ошибка разбора JSON"}`, http.StatusBadRequest)
+				return
+			}
+			name, err := normalizeTag(tag.Name)
+			if err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+				return
+			}
+
+			if r.Method == http.MethodPost {
+				id, err := store.CreateTag(name)
+				if err != nil {
+					http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]interface{}{"id": id})
+				return
+			}
+
+			if tag.ID == "" {
+				logger.LogMessage("[ERROR] Не указан идентификатор метки")
+				http.Error(w, `{"error":"не указан идентификатор метки"}`, http.StatusBadRequest)
+				return
+			}
+			if err := store.RenameTag(tag.ID, name); err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+				return
+			}
+
+		case http.MethodDelete:
+			id := r.URL.Query().Get("id")
+			if id == "" {
+				logger.LogMessage("[ERROR] Не указан идентификатор метки")
+				http.Error(w, `{"error":"не указан идентификатор метки"}`, http.StatusBadRequest)
+				return
+			}
+			if err := store.DeleteTag(id); err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+				return
+			}
+
+		default:
+			logger.LogMessage("[ERROR] Метод не поддерживается")
+			http.Error(w, `{"error":"метод не поддерживается"}`, http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{})
+	}
+}
+
+// normalizeTag приводит название метки к нижнему регистру и проверяет его:
+// название не пустое, без пробелов и запятых, не длиннее maxTagLength символов
+func normalizeTag(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		logger.LogMessage("[ERROR] Не указано название метки")
+		return "", errors.New("не указано название метки")
+	}
+	if utf8.RuneCountInString(name) > maxTagLength || strings.ContainsFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ','
+	}) {
+		logger.LogMessage("[ERROR] Некорректное название метки: " + name)
+		return "", errors.New("некорректное название метки: " + name)
+	}
+	return name, nil
+}
+
+// normalizeTags нормализует метки задачи и убирает повторы, сохраняя порядок
+func normalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	seen := make(map[string]bool, len(tags))
+	result := []string{}
+	for _, tag := range tags {
+		name, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,183 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go_final_project/internal/logger"
+)
+
+// trashPurgeInterval — период проверки корзины на задачи с истёкшим сроком хранения
+const trashPurgeInterval = time.Hour
+
+// trashRetention — срок хранения задач в корзине, ноль — без ограничения
+var trashRetention = 30 * 24 * time.Hour
+
+// InitTrashRetention задаёт срок хранения задач в корзине в днях.
+// Пустая строка оставляет 30 дней, "0" отключает автоматическую очистку.
+func InitTrashRetention(days string) error {
+	if days == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(days)
+	if err != nil || n < 0 {
+		logger.LogMessage(fmt.Sprintf("[ERROR] Некорректный срок хранения корзины: %s", days))
+		return fmt.Errorf("некорректный срок хранения корзины: %s", days)
+	}
+	trashRetention = time.Duration(n) * 24 * time.Hour
+	logger.LogMessage(fmt.Sprintf("[INFO] Срок хранения задач в корзине: %d дн.", n))
+	return nil
+}
+
+// RunTrashPurger удаляет из корзины задачи с истёкшим сроком хранения
+// при запуске и затем раз в trashPurgeInterval
+func RunTrashPurger(store TaskStore) {
+	for {
+		if err := purgeExpiredTrash(store, time.Now()); err != nil {
+			logger.LogMessage("[ERROR] " + err.Error())
+		}
+		time.Sleep(trashPurgeInterval)
+	}
+}
+
+// TrashHandler управляет корзиной: GET возвращает задачи в корзине,
+// DELETE с id окончательно удаляет задачу, без id — очищает корзину.
+func TrashHandler(store TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+
+		switch r.Method {
+		case http.MethodGet:
+			// Просроченные задачи не показываются, даже если фоновая очистка ещё не прошла
+			if err := purgeExpiredTrash(store, time.Now()); err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+				return
+			}
+			limit, err := pageLimit(r)
+			if err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+				return
+			}
+			var cursor *TaskCursor
+			if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
+				if cursor, err = decodeTrashCursor(cursorStr); err != nil {
+					logger.LogMessage("[ERROR] Некорректный параметр 'cursor': " + cursorStr)
+					http.Error(w, `{"error":"некорректный параметр 'cursor'"}`, http.StatusBadRequest)
+					return
+				}
+			}
+
+			// Лишняя задача в выборке показывает, что за страницей есть ещё задачи
+			tasks, err := store.ListTrash(limit+1, cursor)
+			if err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+				return
+			}
+			response := map[string]interface{}{}
+			if len(tasks) > limit {
+				tasks = tasks[:limit]
+				response["next_cursor"] = encodeTrashCursor(tasks)
+			}
+			for i := range tasks {
+				tasks[i].setDue()
+			}
+			response["tasks"] = tasks
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(response)
+			return
+
+		case http.MethodDelete:
+			if id == "" {
+				if _, err := store.PurgeTrash(time.Time{}); err != nil {
+					http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+					return
+				}
+				break
+			}
+			if _, err := store.GetTrashedTask(id); err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusNotFound)
+				return
+			}
+			if err := store.PurgeTask(id); err != nil {
+				http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+				return
+			}
+
+		default:
+			logger.LogMessage("[ERROR] Метод не поддерживается")
+			http.Error(w, `{"error":"метод не поддерживается"}`, http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{})
+	}
+}
+
+// RestoreHandler возвращает задачу id из корзины в список задач
+func RestoreHandler(store TaskStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			logger.LogMessage("[ERROR] Метод не поддерживается")
+			http.Error(w, `{"error":"метод не поддерживается"}`, http.StatusMethodNotAllowed)
+			return
+		}
+
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			logger.LogMessage("[ERROR] Не указан идентификатор задачи")
+			http.Error(w, `{"error":"не указан идентификатор задачи"}`, http.StatusBadRequest)
+			return
+		}
+		if _, err := store.GetTrashedTask(id); err != nil {
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusNotFound)
+			return
+		}
+		if err := store.RestoreTask(id); err != nil {
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{})
+	}
+}
+
+// purgeExpiredTrash удаляет задачи, пролежавшие в корзине дольше trashRetention
+func purgeExpiredTrash(store TaskStore, now time.Time) error {
+	if trashRetention == 0 {
+		return nil
+	}
+	n, err := store.PurgeTrash(now.Add(-trashRetention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		logger.LogMessage(fmt.Sprintf("[INFO] Из корзины удалено задач: %d", n))
+	}
+	return nil
+}
+
+// encodeTrashCursor возвращает позицию после последней задачи страницы корзины
+func encodeTrashCursor(tasks []Task) string {
+	last := &tasks[len(tasks)-1]
+	cursor := TaskCursor{TrashedAt: trashedAt(last)}
+	cursor.ID, _ = strconv.ParseInt(last.ID, 10, 64)
+	return marshalCursor(cursor)
+}
+
+// decodeTrashCursor разбирает позицию, выданную encodeTrashCursor
+func decodeTrashCursor(str string) (*TaskCursor, error) {
+	cursor, err := unmarshalCursor(str)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.TrashedAt == "" || cursor.ID <= 0 || cursor.Date != "" || cursor.Offset != 0 {
+		return nil, errors.New("позиция не подходит к корзине")
+	}
+	return cursor, nil
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type checklistItem struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Done  bool   `json:"done"`
+}
+
+func getChecklist(t *testing.T, id string) []checklistItem {
+	body, err := requestJSON("api/task/checklist?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]checklistItem
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	return m["checklist"]
+}
+
+func TestChecklist(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	ret, err := postJSON("api/task", map[string]any{
+		"date":  now.Format(`20060102`),
+		"title": "Выпустить v2",
+		"checklist": []map[string]any{
+			{"title": "Обновить changelog"},
+			{"title": "Собрать релиз", "done": true},
+		},
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	release := fmt.Sprint(ret["id"])
+
+	items := getChecklist(t, release)
+	assert.Len(t, items, 2)
+	assert.Equal(t, "Обновить changelog", items[0].Title)
+	assert.False(t, items[0].Done)
+	assert.True(t, items[1].Done)
+
+	ret, err = postJSON("api/task/checklist?id="+release, map[string]any{"title": "Разослать анонс"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotNil(t, ret["id"])
+	ret, err = postJSON("api/task/checklist?id="+release, map[string]any{"title": " "}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	assert.Len(t, getChecklist(t, release), 3)
+
+	// По умолчанию задача с невыполненными пунктами не выполняется
+	ret, err = postJSON("api/task/done?id="+release, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	for _, item := range getChecklist(t, release) {
+		ret, err = postJSON("api/task/checklist?id="+release, map[string]any{"id": item.ID, "title": item.Title, "done": true}, http.MethodPut)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+	}
+	ret, err = postJSON("api/task/done?id="+release, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, release)
+
+	// Каскадное выполнение отмечает пункты чек-листа, и они остаются отмеченными после восстановления
+	ret, err = postJSON("api/task", map[string]any{
+		"date":  now.Format(`20060102`),
+		"title": "Переезд",
+		"checklist": []map[string]any{
+			{"title": "Упаковать вещи", "done": true},
+			{"title": "Заказать грузчиков"},
+		},
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	moving := fmt.Sprint(ret["id"])
+
+	ret, err = postJSON("api/task/done?id="+moving+"&policy=cascade", nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, moving)
+	var pending int
+	err = db.Get(&pending, "SELECT count(*) FROM scheduler_checklist WHERE task_id = ? AND done = 0", moving)
+	assert.NoError(t, err)
+	assert.Zero(t, pending)
+
+	ret, err = postJSON("api/trash/restore?id="+moving, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	items = getChecklist(t, moving)
+	assert.Len(t, items, 2)
+	for _, item := range items {
+		assert.True(t, item.Done, item.Title)
+	}
+
+	// Повторяющаяся задача выполняется каскадом и начинает новое повторение с чистым чек-листом
+	ret, err = postJSON("api/task", map[string]any{
+		"date":   now.Format(`20060102`),
+		"title":  "Уборка",
+		"repeat": "d 7",
+		"checklist": []map[string]any{
+			{"title": "Пропылесосить", "done": true},
+			{"title": "Помыть полы"},
+		},
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	cleaning := fmt.Sprint(ret["id"])
+
+	ret, err = postJSON("api/task/done?id="+cleaning+"&policy=cascade", nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	items = getChecklist(t, cleaning)
+	assert.Len(t, items, 2)
+	for _, item := range items {
+		assert.False(t, item.Done, item.Title)
+	}
+
+	ret, err = postJSON("api/task/done?id="+cleaning+"&policy=ignore", nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/task/checklist?id="+cleaning+"&item="+items[0].ID, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Len(t, getChecklist(t, cleaning), 1)
+
+	ret, err = postJSON("api/task/done?id="+cleaning, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+}
@@ -6,16 +6,23 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
+	_ "modernc.org/sqlite"
 )
 
 type Task struct {
-	ID      int64  `db:"id"`
-	Date    string `db:"date"`
-	Title   string `db:"title"`
-	Comment string `db:"comment"`
-	Repeat  string `db:"repeat"`
+	ID          int64  `db:"id"`
+	Date        string `db:"date"`
+	Title       string `db:"title"`
+	Comment     string `db:"comment"`
+	Repeat      string `db:"repeat"`
+	Time        string `db:"time"`
+	Timezone    string `db:"timezone"`
+	Priority    int    `db:"priority"`
+	ProjectID   *int64 `db:"project_id"`
+	DeletedAt   string `db:"deleted_at"`
+	CompletedAt string `db:"completed_at"`
+	AnchorDate  string `db:"anchor_date"`
 }
 
 func count(db *sqlx.DB) (int, error) {
@@ -29,7 +36,8 @@ func openDB(t *testing.T) *sqlx.DB {
 	if len(envFile) > 0 {
 		dbfile = envFile
 	}
-	db, err := sqlx.Connect("sqlite3", dbfile)
+	// Драйвер тот же, что у сервера: в нём есть модуль FTS5 для триггеров поиска
+	db, err := sqlx.Connect("sqlite", dbfile)
 	assert.NoError(t, err)
 	return db
 }
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type depsTask struct {
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	BlockedBy []string `json:"blocked_by"`
+	Blocking  []string `json:"blocking"`
+	Blocked   bool     `json:"blocked"`
+}
+
+func getDepsTask(t *testing.T, id string) depsTask {
+	body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var task depsTask
+	err = json.Unmarshal(body, &task)
+	assert.NoError(t, err)
+	return task
+}
+
+func getDepsTasks(t *testing.T, query string) map[string]bool {
+	body, err := requestJSON("api/tasks?"+query, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]depsTask
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	blocked := make(map[string]bool)
+	for _, task := range m["tasks"] {
+		blocked[task.Title] = task.Blocked
+	}
+	return blocked
+}
+
+func TestDependencies(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM scheduler; DELETE FROM scheduler_deps")
+	assert.NoError(t, err)
+
+	date := time.Now().Format(`20060102`)
+	buy := addTask(t, task{date: date, title: "Купить краску"})
+	paint := addTask(t, task{date: date, title: "Покрасить забор"})
+	water := addTask(t, task{date: date, title: "Полить газон", repeat: "d 1"})
+	rest := addTask(t, task{date: date, title: "Отдохнуть"})
+
+	for _, dep := range [][2]string{{paint, buy}, {rest, paint}, {rest, water}} {
+		ret, err := postJSON("api/task/deps?id="+dep[0]+"&blocked_by="+dep[1], nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+	}
+
+	// Циклы и зависимость от самой себя запрещены
+	for _, dep := range [][2]string{{buy, rest}, {buy, paint}, {buy, buy}} {
+		ret, err := postJSON("api/task/deps?id="+dep[0]+"&blocked_by="+dep[1], nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], dep)
+	}
+	ret, err := postJSON("api/task/deps?id="+buy+"&blocked_by=100500", nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	task := getDepsTask(t, paint)
+	assert.Equal(t, []string{buy}, task.BlockedBy)
+	assert.Equal(t, []string{rest}, task.Blocking)
+	assert.True(t, task.Blocked)
+
+	assert.Equal(t, map[string]bool{
+		"Купить краску":   false,
+		"Покрасить забор": true,
+		"Полить газон":    false,
+		"Отдохнуть":       true,
+	}, getDepsTasks(t, ""))
+	assert.Equal(t, map[string]bool{"Купить краску": false, "Полить газон": false}, getDepsTasks(t, "blocked=0"))
+
+	// Выполнение задачи снимает блокировку с зависимых, в том числе у повторяющейся задачи
+	ret, err = postJSON("api/task/done?id="+buy, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.False(t, getDepsTask(t, paint).Blocked)
+
+	ret, err = postJSON("api/task/done?id="+water, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, []string{paint}, getDepsTask(t, rest).BlockedBy)
+
+	ret, err = postJSON("api/task/deps?id="+rest+"&blocked_by="+paint, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, map[string]bool{"Покрасить забор": false, "Полить газон": false, "Отдохнуть": false}, getDepsTasks(t, ""))
+
+	// Снятая выполнением зависимость сохраняется в базе
+	var resolved int
+	err = db.Get(&resolved, "SELECT resolved FROM scheduler_deps WHERE task_id = ? AND blocked_by = ?", rest, water)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, resolved)
+}
+
+func TestRecurringDependencies(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM scheduler; DELETE FROM scheduler_deps")
+	assert.NoError(t, err)
+
+	date := time.Now().Format(`20060102`)
+	collect := addTask(t, task{date: date, title: "Собрать мусор", repeat: "d 1"})
+	takeOut := addTask(t, task{date: date, title: "Вынести мусор", repeat: "d 1"})
+	last := addTask(t, task{date: date, title: "Последняя уборка", repeat: "d 1 count 1"})
+	rest := addTask(t, task{date: date, title: "Отдохнуть"})
+	for _, dep := range [][2]string{{takeOut, collect}, {rest, last}} {
+		ret, err := postJSON("api/task/deps?id="+dep[0]+"&blocked_by="+dep[1], nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+	}
+	done := func(id string) {
+		ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+	}
+
+	// Повторяющаяся блокирующая задача снимает блокировку до следующего повторения зависимой
+	assert.True(t, getDepsTask(t, takeOut).Blocked)
+	done(collect)
+	assert.False(t, getDepsTask(t, takeOut).Blocked)
+	done(takeOut)
+	task := getDepsTask(t, takeOut)
+	assert.True(t, task.Blocked)
+	assert.Equal(t, []string{collect}, task.BlockedBy)
+	done(collect)
+	assert.False(t, getDepsTask(t, takeOut).Blocked)
+
+	// Последнее повторение серии тоже снимает блокировку, и она не возвращается при восстановлении
+	done(last)
+	assert.False(t, getDepsTask(t, rest).Blocked)
+	ret, err := postJSON("api/trash/restore?id="+last, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.False(t, getDepsTask(t, rest).Blocked)
+
+	// Удаление блокирующей задачи зависимость не снимает
+	ret, err = postJSON("api/task/deps?id="+rest+"&blocked_by="+collect, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task?id="+collect, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.False(t, getDepsTask(t, rest).Blocked)
+	ret, err = postJSON("api/trash/restore?id="+collect, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, []string{collect}, getDepsTask(t, rest).BlockedBy)
+}
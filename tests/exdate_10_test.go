@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getExDates(t *testing.T, id string) []string {
+	body, err := requestJSON("api/task/exdate?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]string
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	return m["exdates"]
+}
+
+func TestExDate(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	day := func(n int) string {
+		return now.AddDate(0, 0, n).Format(`20060102`)
+	}
+	taskDate := func(id string) string {
+		var task Task
+		err := db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+		assert.NoError(t, err)
+		return task.Date
+	}
+
+	id := addTask(t, task{
+		date:   day(0),
+		title:  "Полить цветы",
+		repeat: "d 1",
+	})
+	assert.Empty(t, getExDates(t, id))
+
+	ret, err := postJSON("api/task/exdate?id="+id+"&date="+day(1), nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, []string{day(1)}, getExDates(t, id))
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, day(2), taskDate(id))
+
+	// Пропуск текущей даты переносит задачу на следующую
+	ret, err = postJSON("api/task/exdate?id="+id+"&date="+day(2), nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, day(3), taskDate(id))
+
+	ret, err = postJSON("api/task/exdate?id="+id+"&date="+day(1), nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, []string{day(2)}, getExDates(t, id))
+
+	ret, err = postJSON("api/task/exdate?id="+id+"&date=ooops", nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	single := addTask(t, task{
+		date:  day(0),
+		title: "Разовая задача",
+	})
+	ret, err = postJSON("api/task/exdate?id="+single+"&date="+day(1), nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/trash?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	var count int
+	err = db.Get(&count, `SELECT count(*) FROM scheduler_exdates WHERE task_id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
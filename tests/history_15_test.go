@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getHistory(t *testing.T, id string) []map[string]string {
+	body, err := requestJSON("api/task/history?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]map[string]string
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	return m["history"]
+}
+
+func TestHistory(t *testing.T) {
+	now := time.Now()
+	day := func(n int) string {
+		return now.AddDate(0, 0, n).Format(`20060102`)
+	}
+
+	id := addTask(t, task{
+		date:   day(0),
+		title:  "Вынести мусор",
+		repeat: "d 2",
+	})
+	assert.Empty(t, getHistory(t, id))
+
+	ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task/done?id="+id, map[string]any{"note": "с опозданием"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	history := getHistory(t, id)
+	assert.Len(t, history, 2)
+	assert.Equal(t, day(2), history[0]["scheduled_date"])
+	assert.Equal(t, "с опозданием", history[0]["note"])
+	assert.Equal(t, day(0), history[1]["scheduled_date"])
+	completed, err := time.Parse(time.RFC3339, history[1]["completed_at"])
+	assert.NoError(t, err)
+	assert.WithinDuration(t, now, completed, time.Minute)
+
+	// Однократная задача удаляется после выполнения, но история остаётся
+	once := addTask(t, task{date: day(0), title: "Сдать отчёт"})
+	ret, err = postJSON("api/task/done?id="+once, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, once)
+	assert.Len(t, getHistory(t, once), 1)
+
+	// Удалённая без выполнений задача в корзине отвечает пустой историей
+	deleted := addTask(t, task{date: day(1), title: "Позвонить в банк"})
+	ret, err = postJSON("api/task?id="+deleted, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	body, err := requestJSON("api/task/history?id="+deleted, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"history":[]}`, string(body))
+
+	// Окончательно удалённой задачи, как и несуществующей, нет
+	for _, purged := range []string{once, deleted} {
+		ret, err = postJSON("api/trash?id="+purged, nil, http.MethodDelete)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+	}
+	for _, missing := range []string{once, deleted, "100500"} {
+		ret, err = postJSON("api/task/history?id="+missing, nil, http.MethodGet)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], missing)
+	}
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+}
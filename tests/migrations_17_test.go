@@ -0,0 +1,29 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrations(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	var versions []int
+	err := db.Select(&versions, `SELECT version FROM schema_version ORDER BY version`)
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, versions) {
+		for i, v := range versions {
+			assert.Equal(t, i+1, v)
+		}
+	}
+
+	var columns []string
+	err = db.Select(&columns, `SELECT name FROM pragma_table_info('scheduler')`)
+	assert.NoError(t, err)
+	for _, column := range []string{"id", "date", "title", "comment", "repeat", "time",
+		"timezone", "priority", "project_id", "deleted_at", "completed_at", "anchor_date"} {
+		assert.Contains(t, columns, column)
+	}
+}
@@ -2,6 +2,8 @@ package tests
 
 import (
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 	"strings"
 	"testing"
@@ -36,6 +38,13 @@ func TestNextDate(t *testing.T) {
 		{"20240320", "d 401", ""},
 		{"20231225", "d 12", `20240130`},
 		{"20240228", "d 1", "20240229"},
+		{"20240120", "d 7 until 20240201", "20240127"},
+		{"20240120", "d 7 until 20240126", ""},
+		{"20240120", "d 7 until 2024", ""},
+		{"20240120", "d 7 count 3", "20240127"},
+		{"20240120", "d 7 count 1", ""},
+		{"20240120", "d 7 count 0", ""},
+		{"20240120", "d 7 count", ""},
 	}
 	check := func() {
 		for _, v := range tbl {
@@ -53,6 +62,34 @@ func TestNextDate(t *testing.T) {
 		}
 	}
 	check()
+
+	// Исчерпанная серия отличается от ошибки в правиле
+	for _, v := range []struct {
+		date, repeat string
+		code         int
+		body         string
+	}{
+		{"20240120", "d 7 until 20240126", http.StatusUnprocessableEntity, "повторений больше нет"},
+		{"20240120", "d 7 count 1", http.StatusUnprocessableEntity, "повторений больше нет"},
+		{"20240120", "d 7 count 0", http.StatusBadRequest, ""},
+		{"20240126", "ooops", http.StatusBadRequest, ""},
+	} {
+		resp, err := http.Get(getURL(fmt.Sprintf("api/nextdate?now=20240126&date=%s&repeat=%s",
+			v.date, url.QueryEscape(v.repeat))))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, v.code, resp.StatusCode, v.repeat)
+		if v.body != "" {
+			assert.Equal(t, v.body, strings.TrimSpace(string(body)), v.repeat)
+		} else {
+			assert.NotEqual(t, "повторений больше нет", strings.TrimSpace(string(body)), v.repeat)
+		}
+	}
+
 	if !FullNextDate {
 		return
 	}
@@ -74,6 +111,54 @@ func TestNextDate(t *testing.T) {
 		{"20240126", "w 7", "20240128"},
 		{"20230126", "w 4,5", "20240201"},
 		{"20230226", "w 8,4,5", ""},
+		{"20240126", "wm 1.1", "20240205"},
+		{"20240101", "wm -1.3", "20240131"},
+		{"20240126", "wm -1.5", "20240223"},
+		{"20240126", "wm 2.2,4.2", "20240213"},
+		{"20240126", "wm 5.4", "20240229"},
+		{"20240126", "wm -2.7", "20240218"},
+		{"20240126", "wm 6.1", ""},
+		{"20240126", "wm 1.8", ""},
+		{"20240126", "wm 1", ""},
+		{"20240126", "wm", ""},
+		{"20240101", "w 1 every 2", "20240129"},
+		{"20240108", "w 1 every 2", "20240205"},
+		{"20240115", "w 1,4 every 3", "20240205"},
+		{"20240115", "m 15 every 3", "20240415"},
+		{"20231130", "m -1 every 3", "20240229"},
+		{"20240108", "wm 2.1 every 2", "20240311"},
+		{"20240126", "y every 2", "20260126"},
+		{"20200301", "y every 4", "20240301"},
+		{"20240120", "d 7 every 2", ""},
+		{"20240120", "w 1 every 0", ""},
+		{"20240101", "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", "20240129"},
+		{"20240120", "FREQ=DAILY;INTERVAL=7", "20240127"},
+		{"20240126", "RRULE:FREQ=MONTHLY;BYDAY=-1FR", "20240223"},
+		{"20240126", "RRULE:FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=1", "20240301"},
+		{"20240120", "RRULE:FREQ=DAILY;INTERVAL=7;COUNT=1", ""},
+		{"20240126", "RRULE:FREQ=SECONDLY", ""},
+		{"20240126", "RRULE:FREQ=MONTHLY;BYSETPOS=1;BYDAY=MO", ""},
+		{"20240126", "RRULE:FREQ=WEEKLY", ""},
+		{"20240126", "bd 1", "20240129"},
+		{"20240122", "bd 3", "20240130"},
+		{"20240115", "m 3 roll next", "20240205"},
+		{"20240115", "m 3 roll prev", "20240202"},
+		{"20240301", "m -1 roll prev", "20240329"},
+		{"20240329", "m -1 roll prev", "20240430"},
+		{"20240126", "bd 1 every 2", ""},
+		{"20240126", "m 1 roll up", ""},
+		{"20240110", "d 7 from done", "20240202"},
+		{"20240110", "w 1 from done", "20240129"},
+		{"20240110", "d 7 from later", ""},
+		{"20240125", "h 4", "20240126"},
+		{"20240126", "min 90 between 09:00-18:00", "20240126"},
+		{"20240125", "RRULE:FREQ=HOURLY;INTERVAL=2", "20240126"},
+		{"20240126", "h 0", ""},
+		{"20240126", "h 25", ""},
+		{"20240126", "h 4 every 2", ""},
+		{"20240126", "h 4 roll next", ""},
+		{"20240126", "min 30 between 18:00-09:00", ""},
+		{"20240126", "d 1 between 09:00-18:00", ""},
 	}
 	check()
 }
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextDates(t *testing.T) {
+	tbl := []struct {
+		query string
+		want  []string
+	}{
+		{"date=20240120&repeat=d+7&count=3", []string{"20240127", "20240203", "20240210"}},
+		{"date=20240120&repeat=" + url.QueryEscape("d 7 count 3"), []string{"20240127", "20240203"}},
+		{"date=20240101&repeat=w+1&from=20240201&to=20240229", []string{"20240205", "20240212", "20240219", "20240226"}},
+		{"date=20240126&repeat=" + url.QueryEscape("m -1 until 20240401"), []string{"20240131", "20240229", "20240331"}},
+		{"date=20240120&repeat=d+7&count=3&exdate=20240127", []string{"20240203", "20240210", "20240217"}},
+		{"date=20240101&repeat=w+1&from=20240201&to=20240229&exdate=20240212", []string{"20240205", "20240219", "20240226"}},
+		{"date=20240126&time=09:00&count=4&repeat=" + url.QueryEscape("h 4 between 09:00-18:00"),
+			[]string{"20240126 13:00", "20240126 17:00", "20240127 09:00", "20240127 13:00"}},
+		{"date=20240126&time=23:30&repeat=min+45&count=2", []string{"20240127 00:15", "20240127 01:00"}},
+		{"date=20240126&repeat=h+8&count=4&exdate=20240127", []string{"20240126 08:00", "20240126 16:00", "20240128 00:00", "20240128 08:00"}},
+		{"date=20240101&repeat=d+1&from=20240201&to=20240601&count=2", []string{"20240201", "20240202"}},
+		{"date=20240301&count=4&repeat=" + url.QueryEscape("m 1 every 3 roll prev"), []string{"20240531", "20240830", "20241129", "20250228"}},
+	}
+	for _, v := range tbl {
+		body, err := requestJSON("api/nextdates?now=20240126&"+v.query, nil, http.MethodGet)
+		assert.NoError(t, err)
+
+		var m struct {
+			Dates []string `json:"dates"`
+		}
+		err = json.Unmarshal(body, &m)
+		assert.NoError(t, err)
+		assert.Equal(t, v.want, m.Dates, v.query)
+	}
+
+	for _, query := range []string{
+		"date=20240120&repeat=k+34",
+		"date=ooops&repeat=d+1",
+		"date=20240120&repeat=d+1&count=0",
+		"date=20240120&repeat=d+1&to=2024",
+		"date=20240120&repeat=d+1&exdate=2024",
+		"date=20240120&repeat=h+1&time=25:00",
+		// Интервал длиннее лимита в 100 дат не обрезается молча
+		"date=20240101&repeat=d+1&from=20240201&to=20240601",
+	} {
+		body, err := requestJSON("api/nextdates?now=20240126&"+query, nil, http.MethodGet)
+		assert.NoError(t, err)
+
+		var m map[string]any
+		err = json.Unmarshal(body, &m)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, m["error"], query)
+	}
+}
+
+func TestDescribeRule(t *testing.T) {
+	tbl := []struct {
+		repeat string
+		lang   string
+		want   string
+	}{
+		{"d 1", "ru", "Каждый день"},
+		{"d 7", "en", "Every 7 days"},
+		{"d 21", "ru", "Раз в 21 день"},
+		{"w 1,4", "ru", "Каждую неделю по понедельникам и четвергам"},
+		{"w 1,3,5 every 2", "en", "Every 2 weeks on Monday, Wednesday and Friday"},
+		{"m 1,15 3,6,9,12", "ru", "Каждый месяц 1-го и 15-го числа в марте, июне, сентябре и декабре"},
+		{"m 1,-1", "en", "Every month on day 1 and the last day"},
+		{"wm 1.1,-1.5", "ru", "Каждый месяц в первый понедельник и в последнюю пятницу"},
+		{"wm 3.7 every 3", "en", "Every 3 months on the third Sunday"},
+		{"y every 2", "ru", "Раз в 2 года"},
+		{"d 1 count 3", "ru", "Каждый день, всего 3 раза"},
+		{"RRULE:FREQ=YEARLY;UNTIL=20300101", "en", "Every year, until January 1, 2030"},
+		{"bd 5", "ru", "Раз в 5 рабочих дней"},
+		{"m 1 roll next", "en", "Every month on day 1, moved to the next business day"},
+		{"m -1 roll prev", "ru", "Каждый месяц в последний день с переносом на предыдущий рабочий день"},
+		{"h 1", "ru", "Каждый час"},
+		{"h 4 between 09:00-18:00", "ru", "Раз в 4 часа с 09:00 до 18:00"},
+		{"min 15 between 08:30-17:00", "en", "Every 15 minutes between 08:30 and 17:00"},
+	}
+	for _, v := range tbl {
+		query := "date=20240126&repeat=" + url.QueryEscape(v.repeat) + "&lang=" + v.lang
+		body, err := requestJSON("api/nextdates?"+query, nil, http.MethodGet)
+		assert.NoError(t, err)
+
+		var m map[string]any
+		err = json.Unmarshal(body, &m)
+		assert.NoError(t, err)
+		assert.Equal(t, v.want, m["description"], v.repeat)
+	}
+
+	// Описание на /api/nextdate по запросу
+	body, err := requestJSON("api/nextdate?now=20240126&date=20240120&describe=1&lang=en&repeat="+
+		url.QueryEscape("d 7 until 20240301"), nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string]string
+	assert.NoError(t, json.Unmarshal(body, &m))
+	assert.Equal(t, map[string]string{"date": "20240127", "description": "Every 7 days, until March 1, 2024"}, m)
+
+	body, err = requestJSON("api/nextdate?now=20240126&date=20240120&describe=1&repeat="+url.QueryEscape("d 7"), nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(body, &m))
+	assert.Equal(t, "Раз в 7 дней", m["description"])
+
+	for _, query := range []string{"describe=yes", "describe=1&lang=de"} {
+		resp, err := http.Get(getURL("api/nextdate?now=20240126&date=20240120&repeat=d+7&" + query))
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagination(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+
+	// Задач больше, чем помещается в список по умолчанию, и у многих совпадают даты
+	now := time.Now()
+	ids := map[string]bool{}
+	for i := 0; i < 60; i++ {
+		params := map[string]any{
+			"date":  now.AddDate(0, 0, i%7).Format(`20060102`),
+			"title": fmt.Sprintf("Задача %d", i),
+		}
+		ret, err := postJSON("api/task", params, http.MethodPost)
+		require.NoError(t, err)
+		require.NotNil(t, ret["id"])
+		ids[fmt.Sprint(ret["id"])] = true
+	}
+
+	ret, err := postJSON("api/tasks", nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Len(t, ret["tasks"], 50)
+	assert.NotEmpty(t, ret["next_cursor"])
+	assert.Nil(t, ret["total"])
+
+	// Все страницы вместе содержат каждую задачу ровно один раз, по порядку дат
+	walk := func(params url.Values) []string {
+		var seen []string
+		lastDate := ""
+		cursor := ""
+		for page := 0; page < 20; page++ {
+			params.Set("limit", "7")
+			if cursor != "" {
+				params.Set("cursor", cursor)
+			}
+			ret, err := postJSON("api/tasks?"+params.Encode(), nil, http.MethodGet)
+			require.NoError(t, err)
+			require.Nil(t, ret["error"])
+			tasks, _ := ret["tasks"].([]any)
+			assert.LessOrEqual(t, len(tasks), 7)
+			for _, item := range tasks {
+				task := item.(map[string]any)
+				seen = append(seen, fmt.Sprint(task["id"]))
+				if params.Get("search") == "" {
+					assert.LessOrEqual(t, lastDate, task["date"])
+					lastDate = task["date"].(string)
+				}
+			}
+			next, ok := ret["next_cursor"].(string)
+			if !ok {
+				return seen
+			}
+			cursor = next
+		}
+		t.Fatal("слишком много страниц")
+		return nil
+	}
+
+	seen := walk(url.Values{})
+	assert.Len(t, seen, len(ids))
+	for _, id := range seen {
+		assert.True(t, ids[id], id)
+		delete(ids, id)
+	}
+	assert.Empty(t, ids)
+
+	// Поиск по тексту листается в порядке релевантности
+	seen = walk(url.Values{"search": {"задача"}})
+	assert.Len(t, seen, 60)
+	unique := map[string]bool{}
+	for _, id := range seen {
+		unique[id] = true
+	}
+	assert.Len(t, unique, 60)
+
+	// total совпадает с числом задач в базе, а не с размером страницы
+	var stored int
+	err = db.Get(&stored, "SELECT count(*) FROM scheduler")
+	assert.NoError(t, err)
+	ret, err = postJSON("api/tasks?total=1&limit=5", nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Len(t, ret["tasks"], 5)
+	assert.Equal(t, "60", ret["total"])
+	assert.Equal(t, fmt.Sprint(stored), ret["total"])
+
+	ret, err = postJSON("api/tasks?total=1&limit=500&search="+url.QueryEscape("due:today"), nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Len(t, ret["tasks"], 9)
+	assert.Equal(t, "9", ret["total"])
+	assert.Nil(t, ret["next_cursor"])
+
+	// Позиция из обычного списка не подходит для поиска по тексту
+	ret, err = postJSON("api/tasks?limit=5", nil, http.MethodGet)
+	assert.NoError(t, err)
+	keyset := ret["next_cursor"].(string)
+	ret, err = postJSON("api/tasks?limit=5&search="+url.QueryEscape("задача"), nil, http.MethodGet)
+	assert.NoError(t, err)
+	ranked := ret["next_cursor"].(string)
+
+	for _, query := range []string{
+		"limit=0",
+		"limit=-1",
+		"limit=501",
+		"limit=abc",
+		"cursor=abc",
+		"cursor=" + url.QueryEscape("e30"),
+		"cursor=" + keyset + "&search=" + url.QueryEscape("задача"),
+		"cursor=" + ranked,
+		"total=yes",
+	} {
+		ret, err := postJSON("api/tasks?"+query, nil, http.MethodGet)
+		assert.NoError(t, err, query)
+		assert.NotEmpty(t, ret["error"], query)
+		assert.Nil(t, ret["tasks"], query)
+	}
+}
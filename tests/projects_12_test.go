@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type project struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+	Archived bool   `json:"archived"`
+	Tasks    string `json:"tasks"`
+}
+
+func getProjects(t *testing.T, query string) []project {
+	body, err := requestJSON("api/projects"+query, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]project
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	return m["projects"]
+}
+
+func TestProjects(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM scheduler; DELETE FROM projects")
+	assert.NoError(t, err)
+
+	addProject := func(name, color string) string {
+		ret, err := postJSON("api/projects", map[string]any{"name": name, "color": color}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotNil(t, ret["id"], name)
+		return fmt.Sprint(ret["id"])
+	}
+	personal := addProject("Личное", "#33aa55")
+	shared := addProject("Общее", "")
+
+	for _, v := range []map[string]any{{"name": ""}, {"name": "Дом", "color": "red"}} {
+		ret, err := postJSON("api/projects", v, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], v)
+	}
+
+	date := time.Now().Format(`20060102`)
+	add := func(title, projectID string) string {
+		params := map[string]any{"date": date, "title": title}
+		if projectID != "" {
+			params["project_id"] = projectID
+		}
+		ret, err := postJSON("api/task", params, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotNil(t, ret["id"], title)
+		return fmt.Sprint(ret["id"])
+	}
+	gym := add("Сходить в зал", personal)
+	add("Созвон с командой", shared)
+	add("Без проекта", "")
+
+	ret, err := postJSON("api/task", map[string]any{"date": date, "title": "Потерянная", "project_id": "100500"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	task, err := postJSON("api/task?id="+gym, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, personal, task["project_id"])
+
+	// Проект можно указать и числом
+	projectID, err := strconv.ParseInt(shared, 10, 64)
+	assert.NoError(t, err)
+	_, err = postJSON("api/task", map[string]any{"id": gym, "date": date, "title": "Сходить в зал", "project_id": projectID}, http.MethodPut)
+	assert.NoError(t, err)
+	task, err = postJSON("api/task?id="+gym, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, shared, task["project_id"])
+	_, err = postJSON("api/task", map[string]any{"id": gym, "date": date, "title": "Сходить в зал", "project_id": personal}, http.MethodPut)
+	assert.NoError(t, err)
+
+	assert.Len(t, getTasks(t, ""), 3)
+	titles := func(query string) []string {
+		body, err := requestJSON("api/tasks?"+query, nil, http.MethodGet)
+		assert.NoError(t, err)
+		var m map[string][]map[string]string
+		err = json.Unmarshal(body, &m)
+		assert.NoError(t, err)
+		var titles []string
+		for _, task := range m["tasks"] {
+			titles = append(titles, task["title"])
+		}
+		return titles
+	}
+	assert.Equal(t, []string{"Сходить в зал"}, titles("project="+personal))
+	assert.Equal(t, []string{"Без проекта"}, titles("project=0"))
+
+	// Редактирование без project_id сохраняет проект задачи
+	ret, err = postJSON("api/task", map[string]any{"id": gym, "date": date, "title": "Сходить в бассейн"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"])
+	assert.Equal(t, []string{"Сходить в бассейн"}, titles("project="+personal))
+
+	// Задачи архивного проекта скрыты из общего списка, но доступны по фильтру
+	ret, err = postJSON("api/projects", map[string]any{"id": shared, "name": "Общее", "archived": true}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Len(t, getTasks(t, ""), 2)
+	assert.Equal(t, []string{"Созвон с командой"}, titles("project="+shared))
+	assert.Len(t, getProjects(t, ""), 1)
+	assert.Len(t, getProjects(t, "?archived=1"), 2)
+
+	ret, err = postJSON("api/task", map[string]any{"date": date, "title": "В архив", "project_id": shared}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/projects?id="+personal, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.ElementsMatch(t, []string{"Без проекта", "Сходить в бассейн"}, titles("project=0"))
+	// Задачи удалённого проекта остаются в базе без проекта
+	var projectIDs []sql.NullInt64
+	err = db.Select(&projectIDs, "SELECT project_id FROM scheduler WHERE id = ?", gym)
+	assert.NoError(t, err)
+	if assert.Len(t, projectIDs, 1) {
+		assert.False(t, projectIDs[0].Valid)
+	}
+
+	body, err := requestJSON("api/tasks?project=abc", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var e map[string]any
+	err = json.Unmarshal(body, &e)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, e["error"])
+}
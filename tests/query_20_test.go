@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+
+	now := time.Now()
+	add := func(days int, title, repeat string) {
+		params := map[string]any{"date": now.AddDate(0, 0, days).Format(`20060102`), "title": title, "repeat": repeat}
+		ret, err := postJSON("api/task", params, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotNil(t, ret["id"], title)
+	}
+	// Задачу с прошедшей датой API переносит на сегодня, поэтому она добавляется напрямую
+	_, err = db.Exec("INSERT INTO scheduler (date, title, comment, repeat) VALUES (?, 'Продлить страховку', '', '')",
+		now.AddDate(0, 0, -3).Format(`20060102`))
+	assert.NoError(t, err)
+	add(0, "Полить цветы", "d 3")
+	add(2, "Купить билеты", "")
+	add(10, "Записаться к врачу", "")
+
+	titles := func(search string) []string {
+		var titles []string
+		for _, task := range searchTasks(t, search) {
+			titles = append(titles, task.Title)
+		}
+		return titles
+	}
+
+	assert.Equal(t, []string{"Продлить страховку"}, titles("due:overdue"))
+	assert.Equal(t, []string{"Полить цветы"}, titles("due:today"))
+	assert.Equal(t, []string{"Полить цветы", "Купить билеты"}, titles("due:+7d"))
+	assert.Equal(t, []string{"Продлить страховку", "Купить билеты", "Записаться к врачу"}, titles("repeat:no"))
+	assert.Equal(t, []string{"Купить билеты"}, titles("repeat:no AND due:+7d"))
+	assert.Equal(t, []string{"Продлить страховку", "Записаться к врачу"}, titles("NOT due:+7d"))
+	assert.Equal(t, []string{"Полить цветы", "Записаться к врачу"}, titles("title:полить OR title:записаться"))
+	from, to := now.AddDate(0, 0, 1).Format(`02.01.2006`), now.AddDate(0, 0, 30).Format(`02.01.2006`)
+	assert.Equal(t, []string{"Купить билеты", "Записаться к врачу"}, titles(fmt.Sprintf("date:%s..%s", from, to)))
+	assert.Equal(t, []string{"Купить билеты"}, titles(fmt.Sprintf("date:%s..%s -врачу", from, to)))
+
+	// Слова с двоеточием вне известных полей и поля без значения ищутся как текст
+	add(5, "Re: встреча", "")
+	add(6, "Ссылка http://example.com/docs", "")
+	assert.Equal(t, []string{"Re: встреча"}, titles("Re: встреча"))
+	assert.Equal(t, []string{"Ссылка http://example.com/docs"}, titles("http://example.com"))
+	assert.Empty(t, titles("priority:1"))
+	assert.Empty(t, titles("title:"))
+
+	for _, search := range []string{
+		"(билеты",
+		"билеты)",
+		"()",
+		"OR билеты",
+		"билеты OR",
+		"билеты AND",
+		"NOT",
+		`"купить билеты`,
+		`title:""`,
+		"date:32.01.2025",
+		"date:10.03.2025..01.03.2025",
+		"due:someday",
+		"repeat:maybe",
+	} {
+		ret, err := postJSON("api/tasks?search="+url.QueryEscape(search), nil, http.MethodGet)
+		assert.NoError(t, err, search)
+		assert.NotEmpty(t, ret["error"], search)
+		assert.Nil(t, ret["tasks"], search)
+	}
+}
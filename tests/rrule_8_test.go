@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getRRule(t *testing.T, param, value string) map[string]string {
+	body, err := requestJSON("api/rrule?"+param+"="+url.QueryEscape(value), nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string]string
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	return m
+}
+
+func TestRRule(t *testing.T) {
+	tbl := []struct {
+		repeat string
+		rrule  string
+	}{
+		{"d 7", "RRULE:FREQ=DAILY;INTERVAL=7"},
+		{"w 1,4 every 2", "RRULE:FREQ=WEEKLY;BYDAY=MO,TH;INTERVAL=2"},
+		{"m 1,-1 3,6", "RRULE:FREQ=MONTHLY;BYMONTHDAY=1,-1;BYMONTH=3,6"},
+		{"wm 1.1,-1.5", "RRULE:FREQ=MONTHLY;BYDAY=1MO,-1FR"},
+		{"y until 20300101", "RRULE:FREQ=YEARLY;UNTIL=20300101"},
+		{"d 1 count 5", "RRULE:FREQ=DAILY;INTERVAL=1;COUNT=5"},
+		{"h 4", "RRULE:FREQ=HOURLY;INTERVAL=4"},
+		{"min 30 count 3", "RRULE:FREQ=MINUTELY;INTERVAL=30;COUNT=3"},
+	}
+	for _, v := range tbl {
+		m := getRRule(t, "repeat", v.repeat)
+		assert.Equal(t, v.rrule, m["rrule"], v.repeat)
+
+		m = getRRule(t, "rrule", v.rrule)
+		assert.Equal(t, v.repeat, m["repeat"], v.rrule)
+	}
+
+	for _, v := range []string{"RRULE:FREQ=SECONDLY", "RRULE:FREQ=DAILY;BYHOUR=9", "FREQ=YEARLY;BYWEEKNO=20"} {
+		m := getRRule(t, "rrule", v)
+		assert.NotEmpty(t, m["error"], v)
+	}
+	m := getRRule(t, "repeat", "m 07,19  05,6")
+	assert.Equal(t, "m 7,19 5,6", m["repeat"])
+
+	errs := []struct {
+		repeat string
+		want   string
+	}{
+		{"k 34", "неверное правило повторения: k (позиция 1)"},
+		{"w 1,8", "неверный день недели: 8 (позиция 5)"},
+		{"m 5 1,13", "неверный месяц в правиле месяца: 13 (позиция 7)"},
+		{"d 7 every 2", "условие every не применяется к правилу d: every (позиция 5)"},
+		{"y 1", "лишний элемент правила: 1 (позиция 3)"},
+		{"d", "отсутствует интервал для правила d (позиция 2)"},
+		{"bd 2", "правило bd не выражается в RRULE"},
+		{"m 1 roll next", "условие roll не выражается в RRULE"},
+		{"h 2 between 09:00-18:00", "окно активных часов не выражается в RRULE"},
+		{"d 1 between 09:00-18:00", "условие between применяется только к правилам h и min: between (позиция 5)"},
+	}
+	for _, v := range errs {
+		m = getRRule(t, "repeat", v.repeat)
+		assert.Equal(t, v.want, m["error"], v.repeat)
+	}
+
+	// Позиции относятся к правилу во внутреннем формате, поэтому для RRULE не указываются
+	rruleErrs := []struct {
+		rrule string
+		want  string
+	}{
+		{"FREQ=MONTHLY;BYMONTHDAY=5;BYMONTH=13", "неверный месяц в правиле месяца: 13"},
+		{"FREQ=DAILY;UNTIL=2030XX01", "неверная дата в условии until: 2030XX01"},
+		{"FREQ=WEEKLY;BYDAY=MO;WKST=SU", "поддерживается только WKST=MO: SU"},
+	}
+	for _, v := range rruleErrs {
+		m = getRRule(t, "rrule", v.rrule)
+		assert.Equal(t, v.want, m["error"], v.rrule)
+	}
+	m = getRRule(t, "rrule", "FREQ=WEEKLY;BYDAY=MO;WKST=MO")
+	assert.Equal(t, "w 1", m["repeat"])
+}
+
+func TestLongRepeat(t *testing.T) {
+	var days, months []string
+	for i := 1; i <= 28; i++ {
+		days = append(days, strconv.Itoa(i))
+	}
+	for i := 1; i <= 12; i++ {
+		months = append(months, strconv.Itoa(i))
+	}
+	// Правило длиннее прежнего ограничения столбца repeat в 128 символов
+	rrule := "RRULE:FREQ=MONTHLY;BYMONTHDAY=" + strings.Join(days, ",") +
+		";BYMONTH=" + strings.Join(months, ",") + ";UNTIL=20301231T000000Z"
+	assert.Greater(t, len(rrule), 128)
+
+	id := addTask(t, task{date: "20240101", title: "Длинное правило", repeat: rrule})
+	saved, err := postJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, rrule, saved["repeat"])
+
+	ret, err := postJSON("api/task", map[string]any{"date": "20240101", "title": "Слишком длинное правило",
+		"repeat": "d 1" + strings.Repeat(" ", 1024)}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Equal(t, "правило повторения длиннее 1024 символов", ret["error"])
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type foundTask struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+}
+
+func searchTasks(t *testing.T, search string) []foundTask {
+	body, err := requestJSON("api/tasks?search="+url.QueryEscape(search), nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]foundTask
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	return m["tasks"]
+}
+
+func TestSearch(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+
+	date := time.Now().AddDate(0, 0, 1).Format(`20060102`)
+	add := func(title, comment string) string {
+		ret, err := postJSON("api/task", map[string]any{"date": date, "title": title, "comment": comment}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotNil(t, ret["id"], title)
+		return fmt.Sprint(ret["id"])
+	}
+	milk := add("Купить молоко", "")
+	add("Позвонить маме", "купить подарок к празднику")
+	add("Починить кран", "")
+
+	titles := func(search string) []string {
+		var titles []string
+		for _, task := range searchTasks(t, search) {
+			titles = append(titles, task.Title)
+		}
+		return titles
+	}
+
+	// Регистр кириллицы не важен, совпадение в заголовке идёт первым
+	assert.Equal(t, []string{"Купить молоко", "Позвонить маме"}, titles("купить"))
+	assert.Equal(t, []string{"Купить молоко", "Позвонить маме"}, titles("КУПИТЬ"))
+	assert.ElementsMatch(t, []string{"Позвонить маме", "Починить кран"}, titles("по*"))
+	assert.Equal(t, []string{"Купить молоко"}, titles("моло"))
+	assert.Equal(t, []string{"Позвонить маме"}, titles("купить подар"))
+	assert.Empty(t, titles(`"моло"`))
+	assert.Equal(t, []string{"Позвонить маме"}, titles(`"подарок к празднику"`))
+	assert.Empty(t, titles(`"подарок празднику"`))
+	// Операторы FTS5 в строке поиска — обычный текст
+	assert.Empty(t, titles("купить NEAR молоко"))
+
+	found := searchTasks(t, "подарок")
+	if assert.Len(t, found, 1) {
+		assert.Equal(t, "купить <mark>подарок</mark> к празднику", found[0].Snippet)
+	}
+	// Текст вокруг найденных слов экранируется, чтобы не попасть в страницу как разметка
+	markup := add("Prio <b>x</b>", `<script>alert("x")</script>`)
+	found = searchTasks(t, "prio")
+	if assert.Len(t, found, 1) {
+		assert.Equal(t, "<mark>Prio</mark> &lt;b&gt;x&lt;/b&gt;", found[0].Snippet)
+	}
+	found = searchTasks(t, "alert")
+	if assert.Len(t, found, 1) {
+		assert.Equal(t, "&lt;script&gt;<mark>alert</mark>(&#34;x&#34;)&lt;/script&gt;", found[0].Snippet)
+	}
+	_, err = db.Exec("DELETE FROM scheduler WHERE id = ?", markup)
+	assert.NoError(t, err)
+
+	found = searchTasks(t, "")
+	assert.Len(t, found, 3)
+	for _, task := range found {
+		assert.Empty(t, task.Snippet)
+	}
+
+	// Индекс обновляется при изменении и удалении задачи
+	_, err = postJSON("api/task", map[string]any{"id": milk, "date": date, "title": "Купить хлеб"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, titles("молоко"))
+	assert.Equal(t, []string{"Купить хлеб"}, titles("хлеб"))
+
+	_, err = postJSON("api/task?id="+milk, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, titles("хлеб"))
+	_, err = db.Exec("DELETE FROM scheduler WHERE id = ?", milk)
+	assert.NoError(t, err)
+	var indexed int
+	err = db.Get(&indexed, "SELECT count(*) FROM scheduler_fts WHERE scheduler_fts MATCH 'хлеб'")
+	assert.NoError(t, err)
+	assert.Zero(t, indexed)
+}
@@ -0,0 +1,521 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"go_final_project/internal/database"
+	todo "go_final_project/internal/task"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, todo.NewMemoryStore())
+}
+
+func TestSQLiteStore(t *testing.T) {
+	t.Setenv("TODO_DSN", "")
+	t.Setenv("TODO_DBFILE", filepath.Join(t.TempDir(), "store.db"))
+	require.NoError(t, database.InitDB())
+	defer database.CloseDB()
+
+	testStore(t, todo.NewSQLStore(database.GetDB()))
+}
+
+// TestPostgresStore запускается, если TODO_TEST_POSTGRES_DSN указывает на
+// отдельную базу PostgreSQL: схема в ней пересоздаётся
+func TestPostgresStore(t *testing.T) {
+	dsn := os.Getenv("TODO_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TODO_TEST_POSTGRES_DSN не задан")
+	}
+	t.Setenv("TODO_DSN", dsn)
+	require.NoError(t, database.OpenDB())
+	defer database.CloseDB()
+	require.NoError(t, database.MigrateDown(0, false))
+	require.NoError(t, database.MigrateUp(0, false))
+
+	testStore(t, todo.NewSQLStore(database.GetDB()))
+}
+
+// testStore проверяет, что хранилище ведёт себя одинаково на всех бэкендах
+func testStore(t *testing.T, store todo.TaskStore) {
+	today := time.Now().Format("20060102")
+
+	add := func(title string, priority int, tags ...string) string {
+		id, err := store.AddTask(&todo.Task{Date: today, Title: title, Priority: priority, Tags: tags})
+		require.NoError(t, err)
+		return strconv.FormatInt(id, 10)
+	}
+	query := func(search string) *todo.Query {
+		q, err := todo.ParseQuery(search, time.Now())
+		require.NoError(t, err)
+		return q
+	}
+
+	t.Run("tasks", func(t *testing.T) {
+		id := add("Купить молоко", 1, "дом")
+		got, err := store.GetTask(id)
+		require.NoError(t, err)
+		assert.Equal(t, "Купить молоко", got.Title)
+		assert.Equal(t, []string{"дом"}, got.Tags)
+
+		got.Comment = "обезжиренное"
+		got.Tags = nil
+		require.NoError(t, store.UpdateTask(got))
+		got, err = store.GetTask(id)
+		require.NoError(t, err)
+		assert.Equal(t, "обезжиренное", got.Comment)
+		assert.Equal(t, []string{"дом"}, got.Tags)
+
+		require.NoError(t, store.RescheduleTask(id, "20300101", "10:00"))
+		require.NoError(t, store.SetTaskRepeat(id, "d 1"))
+		got, err = store.GetTask(id)
+		require.NoError(t, err)
+		assert.Equal(t, "20300101", got.Date)
+		assert.Equal(t, "10:00", got.Time)
+		assert.Equal(t, "d 1", got.Repeat)
+
+		require.NoError(t, store.AddExDate(id, "20300102"))
+		got, err = store.GetTask(id)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20300102"}, got.ExDates)
+		require.NoError(t, store.DeleteExDate(id, "20300102"))
+
+		// Пропуск текущей даты записывает исключение и переносит задачу вместе
+		require.NoError(t, store.SkipOccurrence(id, "20300101", &todo.Occurrence{Date: "20300102", Time: "10:00", Repeat: "d 1"}))
+		got, err = store.GetTask(id)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20300101"}, got.ExDates)
+		assert.Equal(t, "20300102", got.Date)
+		require.NoError(t, store.DeleteExDate(id, "20300101"))
+
+		_, err = store.GetTask("999999")
+		assert.Error(t, err)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		add("Отчёт по проекту", 3, "работа", "срочно")
+		add("Позвонить маме", 2, "дом")
+
+		tasks, err := store.ListTasks(todo.TaskFilter{Query: query("отчёт"), Limit: 50})
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, "Отчёт по проекту", tasks[0].Title)
+
+		tasks, err = store.ListTasks(todo.TaskFilter{Query: query("tag:дом"), Limit: 50})
+		require.NoError(t, err)
+		assert.Len(t, tasks, 2)
+
+		tasks, err = store.ListTasks(todo.TaskFilter{Query: query("tag:работа tag:срочно"), Limit: 50})
+		require.NoError(t, err)
+		assert.Len(t, tasks, 1)
+
+		tasks, err = store.ListTasks(todo.TaskFilter{Priorities: []int{2, 3}, Limit: 50})
+		require.NoError(t, err)
+		assert.Len(t, tasks, 2)
+
+		tasks, err = store.ListTasks(todo.TaskFilter{Query: query(time.Now().Format("02.01.2006")), Limit: 1})
+		require.NoError(t, err)
+		assert.Len(t, tasks, 1)
+	})
+
+	t.Run("trash", func(t *testing.T) {
+		id := add("Удалить меня", 0)
+		require.NoError(t, store.TrashTask(id, false))
+		_, err := store.GetTask(id)
+		assert.Error(t, err)
+
+		trashed, err := store.GetTrashedTask(id)
+		require.NoError(t, err)
+		assert.NotEmpty(t, trashed.DeletedAt)
+
+		require.NoError(t, store.RestoreTask(id))
+		_, err = store.GetTask(id)
+		require.NoError(t, err)
+
+		require.NoError(t, store.TrashTask(id, true))
+		tasks, err := store.ListTrash(50, nil)
+		require.NoError(t, err)
+		require.NotEmpty(t, tasks)
+		assert.Equal(t, id, tasks[0].ID)
+
+		// Следующая страница начинается после позиции последней задачи
+		other := add("Удалить и меня", 0)
+		require.NoError(t, store.TrashTask(other, false))
+		tasks, err = store.ListTrash(1, nil)
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		last := tasks[0]
+		lastID, err := strconv.ParseInt(last.ID, 10, 64)
+		require.NoError(t, err)
+		tasks, err = store.ListTrash(1, &todo.TaskCursor{TrashedAt: max(last.DeletedAt, last.CompletedAt), ID: lastID})
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.NotEqual(t, last.ID, tasks[0].ID)
+		assert.ElementsMatch(t, []string{id, other}, []string{last.ID, tasks[0].ID})
+
+		n, err := store.PurgeTrash(time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+		n, err = store.PurgeTrash(time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		_, err = store.GetTrashedTask(id)
+		assert.Error(t, err)
+	})
+
+	t.Run("tags", func(t *testing.T) {
+		tagID, err := store.CreateTag("хобби")
+		require.NoError(t, err)
+		require.NoError(t, store.RenameTag(strconv.FormatInt(tagID, 10), "увлечения"))
+
+		tags, err := store.ListTags()
+		require.NoError(t, err)
+		counts := map[string]int{}
+		for _, tag := range tags {
+			counts[tag.Name] = tag.Tasks
+		}
+		assert.Equal(t, 2, counts["дом"])
+		assert.Contains(t, counts, "увлечения")
+		assert.NotContains(t, counts, "хобби")
+
+		require.NoError(t, store.DeleteTag(strconv.FormatInt(tagID, 10)))
+		tags, err = store.ListTags()
+		require.NoError(t, err)
+		for _, tag := range tags {
+			assert.NotEqual(t, "увлечения", tag.Name)
+		}
+	})
+
+	t.Run("projects", func(t *testing.T) {
+		projectID, err := store.CreateProject(&todo.Project{Name: "Ремонт", Color: "#ff0000"})
+		require.NoError(t, err)
+		id, err := store.AddTask(&todo.Task{Date: today, Title: "Купить краску", ProjectID: &projectID})
+		require.NoError(t, err)
+
+		tasks, err := store.ListTasks(todo.TaskFilter{ProjectID: &projectID, Limit: 50})
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, strconv.FormatInt(id, 10), tasks[0].ID)
+
+		project, err := store.GetProject(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, "Ремонт", project.Name)
+		project.Archived = true
+		require.NoError(t, store.UpdateProject(project))
+
+		tasks, err = store.ListTasks(todo.TaskFilter{Query: query("краску"), Limit: 50})
+		require.NoError(t, err)
+		assert.Empty(t, tasks)
+		projects, err := store.ListProjects(true)
+		require.NoError(t, err)
+		require.Len(t, projects, 1)
+		assert.Equal(t, 1, projects[0].Tasks)
+
+		require.NoError(t, store.DeleteProject(strconv.FormatInt(projectID, 10)))
+		got, err := store.GetTask(strconv.FormatInt(id, 10))
+		require.NoError(t, err)
+		assert.Nil(t, got.ProjectID)
+	})
+
+	t.Run("checklist", func(t *testing.T) {
+		id := add("Собрать чемодан", 0)
+		first, err := store.AddChecklistItem(id, todo.ChecklistItem{Title: "Паспорт"}, 1)
+		require.NoError(t, err)
+		_, err = store.AddChecklistItem(id, todo.ChecklistItem{Title: "Билеты"}, 0)
+		require.NoError(t, err)
+
+		require.NoError(t, store.UpdateChecklistItem(id, todo.ChecklistItem{ID: strconv.FormatInt(first, 10), Title: "Паспорт", Done: true}))
+		got, err := store.GetTask(id)
+		require.NoError(t, err)
+		require.Len(t, got.Checklist, 2)
+		assert.Equal(t, "Билеты", got.Checklist[0].Title)
+		assert.True(t, got.Checklist[1].Done)
+
+		require.NoError(t, store.CompleteChecklist(id))
+		got, err = store.GetTask(id)
+		require.NoError(t, err)
+		assert.True(t, got.Checklist[0].Done)
+		assert.True(t, got.Checklist[1].Done)
+
+		require.NoError(t, store.ResetChecklist(id))
+		require.NoError(t, store.DeleteChecklistItem(id, strconv.FormatInt(first, 10)))
+		got, err = store.GetTask(id)
+		require.NoError(t, err)
+		require.Len(t, got.Checklist, 1)
+		assert.False(t, got.Checklist[0].Done)
+	})
+
+	t.Run("dependencies", func(t *testing.T) {
+		a, b, c := add("Задача A", 0), add("Задача B", 0), add("Задача C", 0)
+		require.NoError(t, store.AddDependency(b, a))
+		require.NoError(t, store.AddDependency(c, b))
+		assert.Error(t, store.AddDependency(a, c))
+		assert.Error(t, store.AddDependency(a, a))
+
+		blocked := true
+		tasks, err := store.ListTasks(todo.TaskFilter{Blocked: &blocked, Limit: 50})
+		require.NoError(t, err)
+		assert.Len(t, tasks, 2)
+
+		got, err := store.GetTask(b)
+		require.NoError(t, err)
+		assert.Equal(t, []string{a}, got.BlockedBy)
+		assert.Equal(t, []string{c}, got.Blocking)
+
+		// Задача в корзине не блокирует, но после восстановления блокирует снова
+		require.NoError(t, store.TrashTask(a, false))
+		got, err = store.GetTask(b)
+		require.NoError(t, err)
+		assert.Empty(t, got.BlockedBy)
+		assert.False(t, got.Blocked)
+		require.NoError(t, store.RestoreTask(a))
+		got, err = store.GetTask(b)
+		require.NoError(t, err)
+		assert.Equal(t, []string{a}, got.BlockedBy)
+
+		// Снятая зависимость сохраняется и возвращается RearmDependencies
+		require.NoError(t, store.UnblockDependents(a))
+		got, err = store.GetTask(b)
+		require.NoError(t, err)
+		assert.Empty(t, got.BlockedBy)
+		assert.False(t, got.Blocked)
+		require.NoError(t, store.RearmDependencies(b))
+		got, err = store.GetTask(b)
+		require.NoError(t, err)
+		assert.Equal(t, []string{a}, got.BlockedBy)
+
+		require.NoError(t, store.UnblockDependents(a))
+		require.NoError(t, store.DeleteDependency(c, b))
+		tasks, err = store.ListTasks(todo.TaskFilter{Blocked: &blocked, Limit: 50})
+		require.NoError(t, err)
+		assert.Empty(t, tasks)
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		blocker, id := add("Купить корм", 0), add("Покормить кота", 0)
+		require.NoError(t, store.AddDependency(id, blocker))
+		_, err := store.AddChecklistItem(id, todo.ChecklistItem{Title: "Миска"}, 0)
+		require.NoError(t, err)
+		require.NoError(t, store.CompleteOccurrence(blocker, todo.Completion{ScheduledDate: today}, nil, false))
+		got, err := store.GetTask(id)
+		require.NoError(t, err)
+		assert.Empty(t, got.BlockedBy)
+		_, err = store.GetTrashedTask(blocker)
+		require.NoError(t, err)
+
+		// Повторение переносится целиком: дата, время, правило, чек-лист и зависимости
+		next := &todo.Occurrence{Date: "20300101", Time: "08:00", Repeat: "d 1 count 2"}
+		require.NoError(t, store.CompleteOccurrence(id, todo.Completion{ScheduledDate: today}, next, true))
+		got, err = store.GetTask(id)
+		require.NoError(t, err)
+		assert.Equal(t, "20300101", got.Date)
+		assert.Equal(t, "08:00", got.Time)
+		assert.Equal(t, "d 1 count 2", got.Repeat)
+		assert.False(t, got.Checklist[0].Done)
+		assert.Empty(t, got.CompletedAt)
+		history, err := store.ListCompletions(id)
+		require.NoError(t, err)
+		assert.Len(t, history, 1)
+
+		// Последнее повторение отмечает чек-лист и попадает в корзину
+		require.NoError(t, store.CompleteOccurrence(id, todo.Completion{ScheduledDate: "20300101"}, nil, true))
+		trashed, err := store.GetTrashedTask(id)
+		require.NoError(t, err)
+		assert.NotEmpty(t, trashed.CompletedAt)
+		require.NoError(t, store.RestoreTask(id))
+		got, err = store.GetTask(id)
+		require.NoError(t, err)
+		assert.True(t, got.Checklist[0].Done)
+		history, err = store.ListCompletions(id)
+		require.NoError(t, err)
+		assert.Len(t, history, 2)
+	})
+
+	t.Run("history", func(t *testing.T) {
+		id := add("Полить цветы", 0)
+		for _, date := range []string{"20240101", "20240102"} {
+			require.NoError(t, store.AddCompletion(id, todo.Completion{
+				ScheduledDate: date,
+				CompletedAt:   date + "T09:00:00Z",
+			}))
+		}
+		history, err := store.ListCompletions(id)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, "20240102", history[0].ScheduledDate)
+
+		require.NoError(t, store.TrashTask(id, false))
+		require.NoError(t, store.PurgeTask(id))
+		history, err = store.ListCompletions(id)
+		require.NoError(t, err)
+		assert.Len(t, history, 2)
+	})
+	t.Run("search", func(t *testing.T) {
+		titles := func(search string) []string {
+			tasks, err := store.ListTasks(todo.TaskFilter{Query: query(search), Limit: 50})
+			require.NoError(t, err)
+			var titles []string
+			for _, task := range tasks {
+				titles = append(titles, task.Title)
+			}
+			return titles
+		}
+
+		assert.ElementsMatch(t, []string{"Купить молоко", "Купить краску"}, titles("КУПИТЬ"))
+		assert.ElementsMatch(t, []string{"Купить молоко", "Купить краску"}, titles("куп*"))
+		// Последнее слово без кавычек ищется как начало слова, остальные — целиком
+		assert.ElementsMatch(t, []string{"Купить молоко", "Купить краску"}, titles("куп"))
+		assert.Equal(t, []string{"Купить молоко"}, titles("купить мол"))
+		assert.Empty(t, titles("куп молоко"))
+		assert.Empty(t, titles(`"куп"`))
+		assert.Equal(t, []string{"Купить молоко"}, titles(`"купить молоко"`))
+		assert.Empty(t, titles(`"молоко купить"`))
+		assert.Equal(t, []string{"Купить молоко"}, titles("купить обезжир*"))
+
+		// Совпадение в заголовке важнее более ранней даты
+		_, err := store.AddTask(&todo.Task{Date: "20300101", Title: "Поплавать", Comment: "Бассейн с тренером"})
+		require.NoError(t, err)
+		id, err := store.AddTask(&todo.Task{Date: "20300105", Title: "Бассейн"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Бассейн", "Поплавать"}, titles("бассейн"))
+
+		tasks, err := store.ListTasks(todo.TaskFilter{Query: query("тренер*"), Limit: 50})
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, "Бассейн с <mark>тренером</mark>", tasks[0].Snippet)
+
+		got, err := store.GetTask(strconv.FormatInt(id, 10))
+		require.NoError(t, err)
+		got.Title = "Каток"
+		require.NoError(t, store.UpdateTask(got))
+		assert.Equal(t, []string{"Поплавать"}, titles("бассейн"))
+		assert.Equal(t, []string{"Каток"}, titles("каток"))
+
+		// Разметка в тексте задачи экранируется, теги добавляются только вокруг найденных слов
+		id, err = store.AddTask(&todo.Task{Date: "20300110", Title: "Prio <b>x</b>"})
+		require.NoError(t, err)
+		tasks, err = store.ListTasks(todo.TaskFilter{Query: query("prio"), Limit: 50})
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, "<mark>Prio</mark> &lt;b&gt;x&lt;/b&gt;", tasks[0].Snippet)
+		tasks, err = store.ListTasks(todo.TaskFilter{Query: query("x"), Limit: 50})
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, "Prio &lt;b&gt;<mark>x</mark>&lt;/b&gt;", tasks[0].Snippet)
+		require.NoError(t, store.TrashTask(strconv.FormatInt(id, 10), false))
+		require.NoError(t, store.PurgeTask(strconv.FormatInt(id, 10)))
+
+		// Буквы ё и е различаются во всех хранилищах так же, как в FTS5
+		id, err = store.AddTask(&todo.Task{Date: "20300115", Title: "Нарядить ёлку"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Нарядить ёлку"}, titles("ЁЛКУ"))
+		assert.Empty(t, titles("елку"))
+		require.NoError(t, store.TrashTask(strconv.FormatInt(id, 10), false))
+		require.NoError(t, store.PurgeTask(strconv.FormatInt(id, 10)))
+	})
+	t.Run("query", func(t *testing.T) {
+		for _, task := range []todo.Task{
+			{Date: "20310301", Title: "Сдать отчёт", Comment: "квартальный", Tags: []string{"работа"}},
+			{Date: "20310315", Title: "Оплатить счёт", Comment: "отчёт приложить", Repeat: "d 30"},
+			{Date: "20310401", Title: "Отчёт по налогам", Tags: []string{"работа", "налоги"}},
+		} {
+			_, err := store.AddTask(&task)
+			require.NoError(t, err)
+		}
+
+		// Условие на 2031 год отделяет задачи этого теста от остальных
+		titlesAt := func(today time.Time, search string) []string {
+			q, err := todo.ParseQuery("date:01.01.2031..31.12.2031 "+search, today)
+			require.NoError(t, err)
+			tasks, err := store.ListTasks(todo.TaskFilter{Query: q, Limit: 50})
+			require.NoError(t, err)
+			titles := []string{}
+			for _, task := range tasks {
+				titles = append(titles, task.Title)
+			}
+			return titles
+		}
+		titles := func(search string) []string {
+			return titlesAt(time.Now(), search)
+		}
+
+		assert.Equal(t, []string{"Сдать отчёт", "Оплатить счёт"}, titles("date:01.03.2031..31.03.2031"))
+		assert.Equal(t, []string{"Сдать отчёт"}, titles("date:..14.03.2031"))
+		assert.Equal(t, []string{"Оплатить счёт"}, titles("repeat:yes"))
+		assert.Equal(t, []string{"Сдать отчёт", "Отчёт по налогам"}, titles("repeat:no"))
+		assert.ElementsMatch(t, []string{"Сдать отчёт", "Отчёт по налогам"}, titles("title:отчёт"))
+		assert.Equal(t, []string{"Оплатить счёт"}, titles("comment:отчёт"))
+		assert.Equal(t, []string{"Оплатить счёт"}, titles(`comment:"отчёт приложить"`))
+		assert.Equal(t, []string{"Оплатить счёт"}, titles("-title:отчёт"))
+		assert.Equal(t, []string{"Оплатить счёт"}, titles("NOT repeat:no"))
+		assert.Equal(t, []string{"Оплатить счёт", "Отчёт по налогам"}, titles("(tag:налоги OR repeat:yes)"))
+		assert.Equal(t, []string{"Сдать отчёт"}, titles("tag:работа AND -tag:налоги"))
+		assert.ElementsMatch(t, []string{"Оплатить счёт", "Отчёт по налогам"}, titles("(оплат* OR налогам)"))
+
+		// Совпадения в заголовке идут раньше совпадений в комментарии
+		found := titles("отчёт")
+		if assert.Len(t, found, 3) {
+			assert.Equal(t, "Оплатить счёт", found[2])
+		}
+
+		march10 := time.Date(2031, 3, 10, 12, 0, 0, 0, time.UTC)
+		assert.Equal(t, []string{"Сдать отчёт"}, titlesAt(march10, "due:overdue"))
+		assert.Equal(t, []string{"Оплатить счёт"}, titlesAt(march10, "due:+7d"))
+		assert.Empty(t, titlesAt(march10, "due:today"))
+		assert.Equal(t, []string{"Оплатить счёт"}, titlesAt(march10.AddDate(0, 0, 4), "due:tomorrow"))
+	})
+	t.Run("cursor", func(t *testing.T) {
+		var want []string
+		for i, date := range []string{"20320102", "20320101", "20320101", "20320103", "20320101"} {
+			id, err := store.AddTask(&todo.Task{Date: date, Title: "Страница " + strconv.Itoa(i), Priority: 4 - i%2})
+			require.NoError(t, err)
+			want = append(want, strconv.FormatInt(id, 10))
+		}
+		// Порядок: дата, приоритет, id
+		want = []string{want[1], want[2], want[4], want[0], want[3]}
+
+		filter := todo.TaskFilter{Query: query("date:01.01.2032..31.12.2032"), Limit: 2}
+		count, err := store.CountTasks(filter)
+		require.NoError(t, err)
+		assert.Equal(t, 5, count)
+
+		var got []string
+		for {
+			tasks, err := store.ListTasks(filter)
+			require.NoError(t, err)
+			for _, task := range tasks {
+				got = append(got, task.ID)
+			}
+			if len(tasks) < filter.Limit {
+				break
+			}
+			last := tasks[len(tasks)-1]
+			id, err := strconv.ParseInt(last.ID, 10, 64)
+			require.NoError(t, err)
+			filter.Cursor = &todo.TaskCursor{Date: last.Date, Priority: last.Priority, ID: id}
+		}
+		assert.Equal(t, want, got)
+
+		// Список по релевантности листается смещением
+		filter = todo.TaskFilter{Query: query("date:01.01.2032..31.12.2032 страница"), Limit: 3}
+		first, err := store.ListTasks(filter)
+		require.NoError(t, err)
+		filter.Cursor = &todo.TaskCursor{Offset: 3}
+		rest, err := store.ListTasks(filter)
+		require.NoError(t, err)
+		assert.Len(t, first, 3)
+		assert.Len(t, rest, 2)
+		got = nil
+		for _, task := range append(first, rest...) {
+			got = append(got, task.ID)
+		}
+		assert.ElementsMatch(t, want, got)
+	})
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type taggedTask struct {
+	ID    string   `json:"id"`
+	Title string   `json:"title"`
+	Tags  []string `json:"tags"`
+}
+
+func getTaggedTasks(t *testing.T, search string) []taggedTask {
+	body, err := requestJSON("api/tasks?search="+url.QueryEscape(search), nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]taggedTask
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	return m["tasks"]
+}
+
+func getTags(t *testing.T) map[string]string {
+	body, err := requestJSON("api/tags", nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]map[string]string
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	tags := make(map[string]string)
+	for _, tag := range m["tags"] {
+		tags[tag["name"]] = tag["tasks"]
+	}
+	return tags
+}
+
+func TestTags(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM scheduler; DELETE FROM scheduler_tags; DELETE FROM tags")
+	assert.NoError(t, err)
+
+	ret, err := postJSON("api/tags", map[string]any{"name": "Billing"}, http.MethodPost)
+	assert.NoError(t, err)
+	billing := fmt.Sprint(ret["id"])
+	assert.NotEmpty(t, billing)
+	ret, err = postJSON("api/tags", map[string]any{"name": "billing"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	for _, name := range []string{"", "два слова", "a,b"} {
+		ret, err = postJSON("api/tags", map[string]any{"name": name}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], name)
+	}
+
+	date := time.Now().Format(`20060102`)
+	add := func(title string, tags []string) string {
+		ret, err := postJSON("api/task", map[string]any{"date": date, "title": title, "tags": tags}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotNil(t, ret["id"], title)
+		return fmt.Sprint(ret["id"])
+	}
+	rent := add("Оплатить аренду", []string{"Work", "billing", "work"})
+	add("Купить продукты", []string{"home"})
+	add("Написать отчёт", []string{"work"})
+
+	task, err := postJSON("api/task?id="+rent, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"billing", "work"}, task["tags"])
+	assert.Equal(t, map[string]string{"billing": "1", "home": "1", "work": "2"}, getTags(t))
+	// Повторы меток без учёта регистра сохраняются одной привязкой
+	var links int
+	err = db.Get(&links, "SELECT count(*) FROM scheduler_tags WHERE task_id = ?", rent)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, links)
+
+	titles := func(search string) []string {
+		var titles []string
+		for _, task := range getTaggedTasks(t, search) {
+			titles = append(titles, task.Title)
+		}
+		return titles
+	}
+	assert.ElementsMatch(t, []string{"Оплатить аренду", "Написать отчёт"}, titles("tag:work"))
+	assert.ElementsMatch(t, []string{"Оплатить аренду", "Купить продукты"}, titles("tag:billing,home"))
+	assert.Equal(t, []string{"Оплатить аренду"}, titles("tag:work tag:billing"))
+	assert.Equal(t, []string{"Написать отчёт"}, titles("отчёт tag:work"))
+	assert.Empty(t, titles("tag:unknown"))
+
+	// Без поля tags метки задачи не меняются, пустой список их снимает
+	ret, err = postJSON("api/task", map[string]any{"id": rent, "date": date, "title": "Оплатить аренду"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"])
+	task, err = postJSON("api/task?id="+rent, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"billing", "work"}, task["tags"])
+
+	ret, err = postJSON("api/task", map[string]any{"id": rent, "date": date, "title": "Оплатить аренду", "tags": []string{}}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"])
+	task, err = postJSON("api/task?id="+rent, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Nil(t, task["tags"])
+
+	ret, err = postJSON("api/tags", map[string]any{"id": billing, "name": "finance"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/tags", map[string]any{"id": billing, "name": "home"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/tags?id="+billing, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, map[string]string{"home": "1", "work": "1"}, getTags(t))
+	// Удалённая метка не оставляет в базе ни строки, ни привязок к задачам
+	var count int
+	err = db.Get(&count, "SELECT (SELECT count(*) FROM tags WHERE id = ?) + (SELECT count(*) FROM scheduler_tags WHERE tag_id = ?)", billing, billing)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+	ret, err = postJSON("api/tags?id="+billing, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+}
@@ -46,6 +46,67 @@ func TestTask(t *testing.T) {
 	assert.Equal(t, task.title, m["title"])
 	assert.Equal(t, task.comment, m["comment"])
 	assert.Equal(t, task.repeat, m["repeat"])
+	assert.Equal(t, "Раз в 5 дней", m["repeat_text"])
+}
+
+func TestTaskTimezone(t *testing.T) {
+	date := time.Now().AddDate(0, 0, 1).Format(`20060102`)
+	iso := date[:4] + "-" + date[4:6] + "-" + date[6:]
+
+	ret, err := postJSON("api/task", map[string]any{
+		"date":     date,
+		"title":    "Созвон с Токио",
+		"time":     "18:30",
+		"timezone": "Asia/Tokyo",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	id := fmt.Sprint(ret["id"])
+
+	body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string]any
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	assert.Equal(t, "18:30", m["time"])
+	assert.Equal(t, "Asia/Tokyo", m["timezone"])
+	assert.Equal(t, iso+"T18:30:00+09:00", m["due"])
+
+	// Изменение без полей time и timezone их не сбрасывает, пустое значение — очищает
+	get := func() map[string]any {
+		body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+		assert.NoError(t, err)
+		var m map[string]any
+		assert.NoError(t, json.Unmarshal(body, &m))
+		return m
+	}
+	_, err = postJSON("api/task", map[string]any{"id": id, "date": date, "title": "Созвон с Токио в пятницу"}, http.MethodPut)
+	assert.NoError(t, err)
+	m = get()
+	assert.Equal(t, "Созвон с Токио в пятницу", m["title"])
+	assert.Equal(t, "18:30", m["time"])
+	assert.Equal(t, "Asia/Tokyo", m["timezone"])
+
+	_, err = postJSON("api/task", map[string]any{"id": id, "date": date, "title": "Созвон с Токио", "time": ""}, http.MethodPut)
+	assert.NoError(t, err)
+	m = get()
+	assert.Nil(t, m["time"])
+	assert.Equal(t, "Asia/Tokyo", m["timezone"])
+
+	id = addTask(t, task{date: date, title: "Без времени"})
+	body, err = requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	assert.Equal(t, iso, m["due"])
+
+	for _, v := range []map[string]any{
+		{"date": date, "title": "Тест", "time": "25:00"},
+		{"date": date, "title": "Тест", "timezone": "Mars/Olympus"},
+	} {
+		ret, err := postJSON("api/task", v, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], v)
+	}
 }
 
 type fulltask struct {
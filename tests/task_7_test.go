@@ -2,6 +2,7 @@ package tests
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"testing"
 	"time"
@@ -52,6 +53,125 @@ func TestDone(t *testing.T) {
 	}
 }
 
+func TestDoneLimited(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	id := addTask(t, task{
+		date:   now.Format(`20060102`),
+		title:  "Принять таблетки",
+		repeat: "d 1 count 2",
+	})
+
+	ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	var task Task
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 1).Format(`20060102`), task.Date)
+	assert.Equal(t, "d 1 count 1", task.Repeat)
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, id)
+}
+
+func TestDoneFromCompletion(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	taskDate := func(id string) string {
+		var task Task
+		err := db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+		assert.NoError(t, err)
+		return task.Date
+	}
+
+	scheduled := addTask(t, task{
+		date:   now.AddDate(0, 0, 10).Format(`20060102`),
+		title:  "Оплатить интернет",
+		repeat: "d 3",
+	})
+	completed := addTask(t, task{
+		date:   now.AddDate(0, 0, 10).Format(`20060102`),
+		title:  "Постирать шторы",
+		repeat: "d 3 from done",
+	})
+
+	for i := 1; i <= 2; i++ {
+		ret, err := postJSON("api/task/done?id="+scheduled, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+		assert.Equal(t, now.AddDate(0, 0, 10+3*i).Format(`20060102`), taskDate(scheduled))
+
+		ret, err = postJSON("api/task/done?id="+completed, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+		assert.Equal(t, now.AddDate(0, 0, 3).Format(`20060102`), taskDate(completed))
+	}
+}
+
+func TestDoneRollEvery(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	id := addTask(t, task{
+		date:   "20300301",
+		title:  "Сдать квартальный отчёт",
+		repeat: "m 1 every 3 roll prev",
+	})
+
+	// Интервал отсчитывается от 1-го числа, даже когда roll переносит дату на конец месяца
+	for _, want := range []string{"20300531", "20300830", "20301129", "20310228"} {
+		ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+
+		var task Task
+		err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+		assert.NoError(t, err)
+		assert.Equal(t, want, task.Date)
+	}
+
+	_, err := postJSON("api/task/done?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+}
+
+func TestDoneSubDaily(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	ret, err := postJSON("api/task", map[string]any{
+		"date":   now.Format(`20060102`),
+		"time":   "00:00",
+		"title":  "Проверить бэкапы",
+		"repeat": "h 1",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	id := fmt.Sprint(ret["id"])
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	// Следующий слот — ближайший час после текущего момента
+	var task Task
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	next := now.Truncate(time.Hour).Add(time.Hour)
+	assert.Equal(t, next.Format(`20060102`), task.Date)
+	assert.Equal(t, next.Format(`15:04`), task.Time)
+
+	_, err = postJSON("api/task/done?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+}
+
 func TestDelTask(t *testing.T) {
 	db := openDB(t)
 	defer db.Close()
@@ -106,3 +106,103 @@ func TestTasks(t *testing.T) {
 	assert.Equal(t, len(tasks), 3)
 
 }
+
+func TestTasksPriority(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+
+	date := time.Now().AddDate(0, 0, 1).Format(`20060102`)
+	ids := map[string]string{}
+	for _, v := range []struct{ title, priority string }{
+		{"Полить цветы", ""},
+		{"Продлить домен", "1"},
+		{"Ответить на письма", "2"},
+	} {
+		params := map[string]any{"date": date, "title": v.title}
+		if v.priority != "" {
+			params["priority"] = v.priority
+		}
+		ret, err := postJSON("api/task", params, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotNil(t, ret["id"], v.title)
+		ids[v.title] = fmt.Sprint(ret["id"])
+	}
+	addTask(t, task{
+		date:  time.Now().Format(`20060102`),
+		title: "Вынести мусор",
+	})
+
+	// Задачи упорядочены по дате, а внутри дня — по приоритету
+	tasks := getTasks(t, "")
+	assert.Len(t, tasks, 4)
+	var titles, priorities []string
+	for _, task := range tasks {
+		titles = append(titles, task["title"])
+		priorities = append(priorities, task["priority"])
+	}
+	assert.Equal(t, []string{"Вынести мусор", "Продлить домен", "Ответить на письма", "Полить цветы"}, titles)
+	assert.Equal(t, []string{"4", "1", "2", "4"}, priorities)
+
+	body, err := requestJSON("api/tasks?priority=1,2", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string][]map[string]string
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	assert.Len(t, m["tasks"], 2)
+
+	for _, priority := range []string{"-1", "5", "high"} {
+		ret, err := postJSON("api/task", map[string]any{
+			"date":     date,
+			"title":    "Неверный приоритет",
+			"priority": priority,
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], priority)
+	}
+
+	body, err = requestJSON("api/tasks?priority=7", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var e map[string]any
+	err = json.Unmarshal(body, &e)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, e["error"])
+
+	// Изменение задачи без поля priority сохраняет её приоритет
+	priority := func(id string) any {
+		body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+		assert.NoError(t, err)
+		var m map[string]any
+		assert.NoError(t, json.Unmarshal(body, &m))
+		return m["priority"]
+	}
+	id := ids["Продлить домен"]
+	_, err = postJSON("api/task", map[string]any{"id": id, "date": date, "title": "Продлить домен и SSL"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", priority(id))
+
+	_, err = postJSON("api/task", map[string]any{"id": id, "date": date, "title": "Продлить домен", "priority": "3"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Equal(t, "3", priority(id))
+
+	ret, err := postJSON("api/task", map[string]any{"id": id, "date": date, "title": "Продлить домен", "priority": "0"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	assert.Equal(t, "3", priority(id))
+
+	// Приоритет можно передать и числом
+	_, err = postJSON("api/task", map[string]any{"id": id, "date": date, "title": "Продлить домен", "priority": 2}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Equal(t, "2", priority(id))
+	ret, err = postJSON("api/task", map[string]any{"date": date, "title": "Числовой приоритет", "priority": 1}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", priority(fmt.Sprint(ret["id"])))
+
+	for _, value := range []any{1.5, true, "один"} {
+		ret, err = postJSON("api/task", map[string]any{"date": date, "title": "Неверный приоритет", "priority": value}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Equal(t, "приоритет задачи должен быть числом или строкой с числом", ret["error"], value)
+	}
+}
@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTrash(t *testing.T) []map[string]string {
+	body, err := requestJSON("api/trash", nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m struct {
+		Tasks []map[string]string `json:"tasks"`
+	}
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	return m.Tasks
+}
+
+func inTrash(t *testing.T, id string) map[string]string {
+	for _, task := range getTrash(t) {
+		if task["id"] == id {
+			return task
+		}
+	}
+	return nil
+}
+
+func TestTrash(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	day := func(n int) string {
+		return now.AddDate(0, 0, n).Format(`20060102`)
+	}
+
+	ret, err := postJSON("api/task", map[string]any{
+		"date":  day(1),
+		"title": "Купить хлеб",
+		"tags":  []string{"покупки"},
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	id := fmt.Sprint(ret["id"])
+
+	// Удалённая задача попадает в корзину и пропадает из списка
+	ret, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, id)
+	trashed := inTrash(t, id)
+	if assert.NotNil(t, trashed) {
+		assert.Equal(t, "Купить хлеб", trashed["title"])
+		deleted, err := time.Parse(time.RFC3339, trashed["deleted_at"])
+		assert.NoError(t, err)
+		assert.WithinDuration(t, now, deleted, time.Minute)
+	}
+
+	// Восстановленная задача возвращается вместе с метками
+	ret, err = postJSON("api/trash/restore?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Nil(t, inTrash(t, id))
+	restored, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal(restored, &m))
+	assert.Equal(t, []any{"покупки"}, m["tags"])
+
+	ret, err = postJSON("api/trash/restore?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	// Выполненная однократная задача тоже попадает в корзину
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, id)
+	trashed = inTrash(t, id)
+	if assert.NotNil(t, trashed) {
+		assert.NotEmpty(t, trashed["completed_at"])
+	}
+
+	// Окончательное удаление
+	ret, err = postJSON("api/trash?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Nil(t, inTrash(t, id))
+	var count int
+	err = db.Get(&count, `SELECT count(*) FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	ret, err = postJSON("api/trash?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	// Задачи с истёкшим сроком хранения удаляются из корзины
+	old := addTask(t, task{date: day(1), title: "Старая задача"})
+	ret, err = postJSON("api/task?id="+old, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	_, err = db.Exec(`UPDATE scheduler SET deleted_at = ? WHERE id = ?`,
+		now.AddDate(0, 0, -31).UTC().Format(time.RFC3339), old)
+	assert.NoError(t, err)
+	assert.Nil(t, inTrash(t, old))
+	err = db.Get(&count, `SELECT count(*) FROM scheduler WHERE id=?`, old)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestTrashPages(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	date := time.Now().AddDate(0, 0, 1).Format(`20060102`)
+	var ids []string
+	for i := 0; i < 5; i++ {
+		id := addTask(t, task{date: date, title: fmt.Sprintf("Удалённая задача %d", i)})
+		ret, err := postJSON("api/task?id="+id, nil, http.MethodDelete)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+		ids = append([]string{id}, ids...)
+	}
+
+	// Страницы корзины идут от последней удалённой задачи без повторов и пропусков
+	var seen []string
+	query := "api/trash?limit=2"
+	for page := 0; page < 3; page++ {
+		ret, err := postJSON(query, nil, http.MethodGet)
+		assert.NoError(t, err)
+		tasks, _ := ret["tasks"].([]any)
+		assert.LessOrEqual(t, len(tasks), 2)
+		for _, item := range tasks {
+			seen = append(seen, fmt.Sprint(item.(map[string]any)["id"]))
+		}
+		if ret["next_cursor"] == nil {
+			break
+		}
+		query = "api/trash?limit=2&cursor=" + fmt.Sprint(ret["next_cursor"])
+	}
+	if assert.GreaterOrEqual(t, len(seen), len(ids)) {
+		assert.Equal(t, ids, seen[:len(ids)])
+	}
+
+	for _, query := range []string{"limit=0", "limit=501", "limit=x", "cursor=oops", "cursor=eyJkIjoiMjAyNDAxMDEiLCJpIjoxfQ"} {
+		ret, err := postJSON("api/trash?"+query, nil, http.MethodGet)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], query)
+	}
+}
+
+func TestTrashDependencies(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	date := time.Now().Format(`20060102`)
+	buy := addTask(t, task{date: date, title: "Купить плитку"})
+	lay := addTask(t, task{date: date, title: "Положить плитку"})
+	ret, err := postJSON("api/task/deps?id="+lay+"&blocked_by="+buy, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	// Пока блокирующая задача в корзине, она не блокирует, но зависимость сохраняется
+	ret, err = postJSON("api/task?id="+buy, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	task := getDepsTask(t, lay)
+	assert.Empty(t, task.BlockedBy)
+	assert.False(t, task.Blocked)
+
+	ret, err = postJSON("api/trash/restore?id="+buy, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	task = getDepsTask(t, lay)
+	assert.Equal(t, []string{buy}, task.BlockedBy)
+	assert.True(t, task.Blocked)
+	assert.Equal(t, []string{lay}, getDepsTask(t, buy).Blocking)
+
+	// Зависимая задача после восстановления снова ждёт блокирующую
+	ret, err = postJSON("api/task?id="+lay, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Empty(t, getDepsTask(t, buy).Blocking)
+	ret, err = postJSON("api/trash/restore?id="+lay, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, []string{buy}, getDepsTask(t, lay).BlockedBy)
+
+	// Окончательное удаление убирает и зависимости
+	ret, err = postJSON("api/task?id="+buy, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/trash?id="+buy, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	var count int
+	err = db.Get(&count, `SELECT count(*) FROM scheduler_deps WHERE task_id = ? OR blocked_by = ?`, buy, buy)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}